@@ -1,75 +1,97 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"net/http"
+	"os"
 
 	"github.com/RayhanAnandhias/realworld-project-golang/configs"
-	"github.com/RayhanAnandhias/realworld-project-golang/pkg/controllers"
-	"github.com/RayhanAnandhias/realworld-project-golang/pkg/routes"
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-var (
-	server *gin.Engine
-
-	TagController      controllers.TagController
-	TagRouteController routes.TagRouteController
-
-	UserController      controllers.UserController
-	UserRouteController routes.UserRouteController
-
-	ArticleController      controllers.ArticleController
-	ArticleRouteController routes.ArticleRouteController
+type command struct {
+	Name  string
+	Usage string
+	Run   func(args []string) error
+}
 
-	CommentController controllers.CommentController
-)
+var commands = []command{
+	{Name: "serve", Usage: "start the API server (the default)", Run: runServe},
+	{Name: "migrate", Usage: "migrate [up [N] | down [N] | status | force VERSION]", Run: runMigrate},
+	{Name: "seed", Usage: "load demo data", Run: runSeed},
+	{Name: "user", Usage: "user create | promote | disable | reset-password", Run: runUser},
+	{Name: "reindex", Usage: "rebuild the search index and the article counters", Run: runReindex},
+}
 
-func init() {
-	config, err := configs.LoadConfig(".")
-	if err != nil {
-		log.Fatal("? Could not load environment variables", err)
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) != 0 {
+		name, args = args[0], args[1:]
 	}
 
-	configs.ConnectDB(&config)
-
-	TagController = controllers.NewTagController(configs.DB)
-	TagRouteController = routes.NewTagRouteController(TagController)
+	for _, c := range commands {
+		if c.Name == name {
+			if err := c.Run(args); err != nil && !errors.Is(err, flag.ErrHelp) {
+				log.Fatalf("? %s: %v", name, err)
+			}
+			return
+		}
+	}
 
-	UserController = controllers.NewUserController(configs.DB)
-	UserRouteController = routes.NewUserRouteController(UserController)
+	switch name {
+	case "help", "-h", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+}
 
-	ArticleController = controllers.NewArticleController(configs.DB)
-	CommentController = controllers.NewCommentController(configs.DB)
-	ArticleRouteController = routes.NewArticleRouteController(ArticleController, CommentController)
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: main <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", c.Name, c.Usage)
+	}
+}
 
-	server = gin.Default()
+// newFlagSet returns the flags of a command, printing usage on errors.
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: main %s\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags
 }
 
-func main() {
-	config, err := configs.LoadConfig("../")
+func loadConfig() (*configs.Config, error) {
+	config, err := configs.LoadConfig(".")
 	if err != nil {
-		log.Fatal("? Could not load environment variables", err)
+		return nil, fmt.Errorf("could not load config: %w", err)
 	}
+	return &config, nil
+}
 
-	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowOrigins = []string{"http://localhost:3000", config.ClientOrigin}
-	corsConfig.AllowCredentials = true
-
-	server.Use(cors.New(corsConfig))
-
-	router := server.Group("/api")
-	router.GET("/healthchecker", func(ctx *gin.Context) {
-		message := "Welcome to Realworld Project"
-		ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": message})
-	})
+// connectMaintenance connects for commands other than serve. They go through
+// whole tables, so queries aren't held to the per-query deadline.
+func connectMaintenance() (*configs.Config, *gorm.DB, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	TagRouteController.TagRoute(router)
-	UserRouteController.UserRoute(router)
-	UserRouteController.SingleUserRoute(router)
-	UserRouteController.ProfileRoute(router)
-	ArticleRouteController.ArticleRoute(router)
+	config.DBQueryTimeout = 0
+	DB, err := configs.ConnectDB(config)
+	return config, DB, err
+}
 
-	log.Fatal(server.Run(":" + config.ServerPort))
+func closeDB(DB *gorm.DB) {
+	if sqlDB, err := DB.DB(); err == nil {
+		sqlDB.Close()
+	}
 }
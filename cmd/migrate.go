@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/migrations"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/migrate"
+)
+
+const migrateUsage = "migrate [up [N] | down [N] | status | force VERSION]"
+
+func runMigrate(args []string) error {
+	flags := newFlagSet("migrate", migrateUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	action := "up"
+	if len(args) != 0 {
+		action, args = args[0], args[1:]
+	}
+
+	number := 0
+	if len(args) > 1 {
+		return fmt.Errorf("usage: %s", migrateUsage)
+	} else if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return fmt.Errorf("%q is not a number of migrations or a version", args[0])
+		}
+		number = n
+	}
+
+	loaded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	_, DB, err := connectMaintenance()
+	if err != nil {
+		return err
+	}
+	defer closeDB(DB)
+
+	migrator := migrate.NewMigrator(DB, loaded)
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(number)
+		for _, migration := range applied {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("the schema is up to date")
+		}
+		return err
+
+	case "down":
+		// reverting everything by accident is worse than typing the number
+		if number == 0 {
+			number = 1
+		}
+		reverted, err := migrator.Down(number)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-24s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+
+	case "force":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: %s", migrateUsage)
+		}
+		if err := migrator.Force(number); err != nil {
+			return err
+		}
+		fmt.Printf("marked migrations up to %04d as applied\n", number)
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate action %q, usage: %s", action, migrateUsage)
+}
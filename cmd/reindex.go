@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/jobs"
+)
+
+func runReindex(args []string) error {
+	flags := newFlagSet("reindex", "reindex")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	_, DB, err := connectMaintenance()
+	if err != nil {
+		return err
+	}
+	defer closeDB(DB)
+
+	// the search vector is a generated column, rebuilding its index and the
+	// planner statistics is all there is to do
+	if err := DB.Exec(`REINDEX INDEX "articles_search_vector_idx"`).Error; err != nil {
+		return err
+	}
+	if err := DB.Exec(`ANALYZE "articles"`).Error; err != nil {
+		return err
+	}
+	fmt.Println("rebuilt the search index")
+
+	fixed, err := jobs.NewCounterReconciler(DB, 0).Reconcile()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("reconciled counters of %d articles\n", fixed)
+
+	return nil
+}
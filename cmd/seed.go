@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/seed"
+)
+
+func runSeed(args []string) error {
+	defaults := seed.DefaultOptions()
+
+	flags := newFlagSet("seed", "seed [-seed N] [-users N] [-articles N] [-follows N] [-likes N] [-comments N] [-password PASSWORD] [-now DATE]")
+	options := defaults
+	flags.Int64Var(&options.Seed, "seed", defaults.Seed, "random seed, the same seed loads the same data")
+	flags.IntVar(&options.Users, "users", defaults.Users, "number of users")
+	flags.IntVar(&options.ArticlesPerUser, "articles", defaults.ArticlesPerUser, "articles written by each user")
+	flags.IntVar(&options.FollowsPerUser, "follows", defaults.FollowsPerUser, "users each user follows")
+	flags.IntVar(&options.LikesPerUser, "likes", defaults.LikesPerUser, "articles each user favorites")
+	flags.IntVar(&options.CommentsPerArticle, "comments", defaults.CommentsPerArticle, "average comments per article")
+	flags.StringVar(&options.Password, "password", defaults.Password, "password of the demo users")
+	now := flags.String("now", defaults.Now.Format(time.DateOnly), "date the data ends at, data spans the 90 days before")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if options.Now, err = time.Parse(time.DateOnly, *now); err != nil {
+		return fmt.Errorf("invalid -now: %w", err)
+	}
+
+	_, DB, err := connectMaintenance()
+	if err != nil {
+		return err
+	}
+	defer closeDB(DB)
+
+	data := seed.Generate(options)
+	if err := seed.Load(DB, &data); err != nil {
+		return err
+	}
+
+	fmt.Printf("loaded %d users, %d articles, %d comments, %d favorites and %d follows\n",
+		len(data.Users), len(data.Articles), len(data.Comments), len(data.Likes), len(data.Follows))
+	return nil
+}
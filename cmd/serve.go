@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/app"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/storage"
+)
+
+func runServe(args []string) error {
+	flags := newFlagSet("serve", "serve")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	loader := configs.NewLoader(".")
+	loaded, err := loader.Load()
+	if err != nil {
+		return fmt.Errorf("could not load config: %w", err)
+	}
+	config := &loaded
+
+	DB, err := configs.ConnectDB(config)
+	if err != nil {
+		return err
+	}
+	// the database goes last, after every request and job is done with it
+	defer closeDB(DB)
+
+	mediaStorage, err := storage.New(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	application, err := app.New(config, DB, mediaStorage)
+	if err != nil {
+		return err
+	}
+	loader.Watch(loaded, application.Settings)
+
+	if err := application.Run(ctx); err != nil {
+		return err
+	}
+
+	log.Println("server stopped")
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const userUsage = "user create -username NAME -email EMAIL [-password PASSWORD] [-admin] | promote USERNAME | disable USERNAME | reset-password [-password PASSWORD] USERNAME"
+
+func runUser(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s", userUsage)
+	}
+
+	action, args := args[0], args[1:]
+	switch action {
+	case "create":
+		return createUser(args)
+	case "promote":
+		return updateUser("user promote", args, `UPDATE users SET role = ?, updated_at = now() WHERE username = ?`, models.UserRoleAdmin)
+	case "disable":
+		return updateUser("user disable", args, `UPDATE users SET status = ?, updated_at = now() WHERE username = ?`, models.UserStatusDisabled)
+	case "reset-password":
+		return resetPassword(args)
+	}
+
+	return fmt.Errorf("unknown user action %q, usage: %s", action, userUsage)
+}
+
+// passwordOrGenerated returns password, or a random one that is printed
+// since nobody knows it yet.
+func passwordOrGenerated(password string) (string, error) {
+	if len(password) != 0 {
+		return password, nil
+	}
+
+	generated, err := utils.GeneratePassword()
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("generated password:", generated)
+	return generated, nil
+}
+
+func createUser(args []string) error {
+	flags := newFlagSet("user create", "user create -username NAME -email EMAIL [-password PASSWORD] [-admin]")
+	username := flags.String("username", "", "username of the account")
+	email := flags.String("email", "", "email of the account")
+	password := flags.String("password", "", "password, generated when empty")
+	admin := flags.Bool("admin", false, "give the account admin privileges")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if len(*username) == 0 || len(*email) == 0 {
+		flags.Usage()
+		return errors.New("username and email are required")
+	}
+
+	plain, err := passwordOrGenerated(*password)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(plain)
+	if err != nil {
+		return err
+	}
+
+	role := models.UserRoleUser
+	if *admin {
+		role = models.UserRoleAdmin
+	}
+
+	_, DB, err := connectMaintenance()
+	if err != nil {
+		return err
+	}
+	defer closeDB(DB)
+
+	query := `INSERT INTO users (username, email, password, role, status) VALUES (?, ?, ?, ?, ?)`
+	if err := DB.Exec(query, *username, strings.ToLower(*email), hashedPassword, role, models.UserStatusActive).Error; err != nil {
+		return err
+	}
+
+	fmt.Printf("created %s %s\n", role, *username)
+	return nil
+}
+
+// updateUser sets a column of the account named in args to value with query.
+func updateUser(name string, args []string, query string, value string) error {
+	flags := newFlagSet(name, name+" USERNAME")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a username is required")
+	}
+	username := flags.Arg(0)
+
+	_, DB, err := connectMaintenance()
+	if err != nil {
+		return err
+	}
+	defer closeDB(DB)
+
+	if err := execOnUser(DB, username, query, value, username); err != nil {
+		return err
+	}
+
+	fmt.Printf("updated %s\n", username)
+	return nil
+}
+
+func resetPassword(args []string) error {
+	flags := newFlagSet("user reset-password", "user reset-password [-password PASSWORD] USERNAME")
+	password := flags.String("password", "", "new password, generated when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a username is required")
+	}
+	username := flags.Arg(0)
+
+	plain, err := passwordOrGenerated(*password)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(plain)
+	if err != nil {
+		return err
+	}
+
+	_, DB, err := connectMaintenance()
+	if err != nil {
+		return err
+	}
+	defer closeDB(DB)
+
+	query := `UPDATE users SET password = ?, updated_at = now() WHERE username = ?`
+	if err := execOnUser(DB, username, query, hashedPassword, username); err != nil {
+		return err
+	}
+
+	fmt.Printf("reset the password of %s\n", username)
+	return nil
+}
+
+func execOnUser(DB *gorm.DB, username string, query string, args ...interface{}) error {
+	process := DB.Exec(query, args...)
+	if process.Error != nil {
+		return process.Error
+	} else if process.RowsAffected == 0 {
+		return fmt.Errorf("user %q not found", username)
+	}
+	return nil
+}
@@ -2,11 +2,26 @@ package configs
 
 import (
 	"time"
+)
+
+const (
+	RegistrationModeOpen     = "open"
+	RegistrationModeInvite   = "invite"
+	RegistrationModeApproval = "approval"
 
-	"github.com/spf13/viper"
+	AccountDeletionCascade   = "cascade"
+	AccountDeletionAnonymize = "anonymize"
+
+	EnvDev  = "dev"
+	EnvTest = "test"
+	EnvProd = "prod"
 )
 
+// Config is read once at startup, except for the embedded Settings which
+// follow the config file while the server runs.
 type Config struct {
+	Env string `mapstructure:"APP_ENV"`
+
 	DBHost         string `mapstructure:"DB_HOST"`
 	DBUserName     string `mapstructure:"DB_USER"`
 	DBUserPassword string `mapstructure:"DB_PASSWORD"`
@@ -14,8 +29,53 @@ type Config struct {
 	DBPort         string `mapstructure:"DB_PORT"`
 	ServerPort     string `mapstructure:"PORT"`
 
+	ServerReadTimeout       time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerReadHeaderTimeout time.Duration `mapstructure:"SERVER_READ_HEADER_TIMEOUT"`
+	ServerWriteTimeout      time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout       time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	ServerMaxHeaderBytes    int           `mapstructure:"SERVER_MAX_HEADER_BYTES"`
+	ShutdownTimeout         time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+
+	// TLS is enabled when both files are set, HTTP on TLSRedirectPort then
+	// redirects to HTTPS
+	TLSCertFile     string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile      string `mapstructure:"TLS_KEY_FILE"`
+	TLSRedirectPort string `mapstructure:"TLS_REDIRECT_PORT"`
+
+	// TrustedProxies are the IPs and CIDR ranges of the reverse proxies
+	// whose X-Forwarded-For header is believed. With none, the client IP is
+	// the address the connection comes from.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	DBQueryTimeout    time.Duration `mapstructure:"DB_QUERY_TIMEOUT"`
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+
+	// ClientOrigin is added to the CORS origins, from before CORS_ORIGINS
 	ClientOrigin string `mapstructure:"CLIENT_ORIGIN"`
 
+	RegistrationMode      string `mapstructure:"REGISTRATION_MODE"`
+	AccountDeletionPolicy string `mapstructure:"ACCOUNT_DELETION_POLICY"`
+
+	StorageBackend    string `mapstructure:"STORAGE_BACKEND"`
+	StorageLocalDir   string `mapstructure:"STORAGE_LOCAL_DIR"`
+	MediaBaseURL      string `mapstructure:"MEDIA_BASE_URL"`
+	UploadMaxSize     int64  `mapstructure:"UPLOAD_MAX_SIZE"`
+	S3Endpoint        string `mapstructure:"S3_ENDPOINT"`
+	S3Region          string `mapstructure:"S3_REGION"`
+	S3Bucket          string `mapstructure:"S3_BUCKET"`
+	S3AccessKeyID     string `mapstructure:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `mapstructure:"S3_SECRET_ACCESS_KEY"`
+
+	MarkdownCacheSize int `mapstructure:"MARKDOWN_CACHE_SIZE"`
+
+	PublishSchedulerInterval time.Duration `mapstructure:"PUBLISH_SCHEDULER_INTERVAL"`
+	TrashRetention           time.Duration `mapstructure:"TRASH_RETENTION"`
+	TrashPurgeInterval       time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"`
+	CounterReconcileInterval time.Duration `mapstructure:"COUNTER_RECONCILE_INTERVAL"`
+
 	AccessTokenPrivateKey  string        `mapstructure:"ACCESS_TOKEN_PRIVATE_KEY"`
 	AccessTokenPublicKey   string        `mapstructure:"ACCESS_TOKEN_PUBLIC_KEY"`
 	RefreshTokenPrivateKey string        `mapstructure:"REFRESH_TOKEN_PRIVATE_KEY"`
@@ -24,20 +84,6 @@ type Config struct {
 	RefreshTokenExpiresIn  time.Duration `mapstructure:"REFRESH_TOKEN_EXPIRED_IN"`
 	AccessTokenMaxAge      int           `mapstructure:"ACCESS_TOKEN_MAXAGE"`
 	RefreshTokenMaxAge     int           `mapstructure:"REFRESH_TOKEN_MAXAGE"`
-}
-
-func LoadConfig(path string) (config Config, err error) {
-	viper.AddConfigPath(path)
-	viper.SetConfigType("env")
-	viper.SetConfigName("dev")
-
-	viper.AutomaticEnv()
-
-	err = viper.ReadInConfig()
-	if err != nil {
-		return
-	}
 
-	err = viper.Unmarshal(&config)
-	return
+	Settings `mapstructure:",squash"`
 }
@@ -2,21 +2,31 @@ package configs
 
 import (
 	"fmt"
-	"log"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// ConnectDB opens the connection pool described by config.
+func ConnectDB(config *Config) (*gorm.DB, error) {
+	// statement_timeout makes Postgres cancel any single query running longer
+	// than the configured deadline
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta statement_timeout=%d", config.DBHost, config.DBUserName, config.DBUserPassword, config.DBName, config.DBPort, config.DBQueryTimeout.Milliseconds())
 
-func ConnectDB(config *Config) {
-	var err error
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta", config.DBHost, config.DBUserName, config.DBUserPassword, config.DBName, config.DBPort)
+	DB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatal("Failed to connect to the Database")
+		return nil, fmt.Errorf("failed to configure the database connection pool: %w", err)
 	}
+	sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.DBConnMaxIdleTime)
 	fmt.Println("? Connected Successfully to the Database")
+
+	return DB, nil
 }
@@ -0,0 +1,181 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+var defaults = map[string]any{
+	"APP_ENV": EnvDev,
+
+	"SERVER_READ_TIMEOUT":        15 * time.Second,
+	"SERVER_READ_HEADER_TIMEOUT": 5 * time.Second,
+	"SERVER_WRITE_TIMEOUT":       30 * time.Second,
+	"SERVER_IDLE_TIMEOUT":        2 * time.Minute,
+	"SERVER_MAX_HEADER_BYTES":    1 << 20,
+	"SHUTDOWN_TIMEOUT":           30 * time.Second,
+
+	"TRUSTED_PROXIES": []string{},
+
+	"DB_QUERY_TIMEOUT":      5 * time.Second,
+	"DB_MAX_OPEN_CONNS":     25,
+	"DB_MAX_IDLE_CONNS":     5,
+	"DB_CONN_MAX_LIFETIME":  30 * time.Minute,
+	"DB_CONN_MAX_IDLE_TIME": 5 * time.Minute,
+
+	"REGISTRATION_MODE":       RegistrationModeOpen,
+	"ACCOUNT_DELETION_POLICY": AccountDeletionAnonymize,
+
+	"STORAGE_BACKEND":   "local",
+	"STORAGE_LOCAL_DIR": "uploads",
+	"MEDIA_BASE_URL":    "/media",
+	"UPLOAD_MAX_SIZE":   5 << 20,
+
+	"MARKDOWN_CACHE_SIZE": 1000,
+
+	"PUBLISH_SCHEDULER_INTERVAL": time.Minute,
+	"TRASH_RETENTION":            30 * 24 * time.Hour,
+	"TRASH_PURGE_INTERVAL":       time.Hour,
+	"COUNTER_RECONCILE_INTERVAL": 24 * time.Hour,
+
+	"CORS_ORIGINS":     []string{"http://localhost:3000"},
+	"RATE_LIMIT":       0,
+	"RATE_LIMIT_BURST": 0,
+	"FEATURE_SEARCH":   true,
+	"FEATURE_UPLOADS":  true,
+}
+
+// Loader reads the config in layers, each overriding the one before:
+//
+//  1. the defaults above
+//  2. the profile file named after APP_ENV (dev, test or prod) in Path, as
+//     .yaml, .toml or .env, for example dev.env; it is optional
+//  3. environment variables
+//  4. for any key, the file named by KEY_FILE, meant for mounted secrets
+type Loader struct {
+	Path string
+
+	// the profile file found by the last Load, watched by Watch
+	file string
+}
+
+func NewLoader(path string) *Loader {
+	return &Loader{Path: path}
+}
+
+// LoadConfig loads and validates the config found in path.
+func LoadConfig(path string) (Config, error) {
+	return NewLoader(path).Load()
+}
+
+func (l *Loader) Load() (config Config, err error) {
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	env := os.Getenv("APP_ENV")
+	if len(env) == 0 {
+		env = EnvDev
+	}
+
+	v.AddConfigPath(l.Path)
+	v.SetConfigName(env)
+	if err = v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return config, fmt.Errorf("could not read %s: %w", v.ConfigFileUsed(), err)
+		}
+	}
+	l.file = v.ConfigFileUsed()
+
+	// unmarshalling only sees environment variables of keys viper knows of
+	for _, key := range configKeys(reflect.TypeOf(config)) {
+		if err = v.BindEnv(key); err != nil {
+			return config, err
+		}
+		if err = v.BindEnv(key + "_FILE"); err != nil {
+			return config, err
+		}
+
+		if file := v.GetString(key + "_FILE"); len(file) != 0 {
+			secret, err := os.ReadFile(file)
+			if err != nil {
+				return config, fmt.Errorf("%s_FILE: %w", key, err)
+			}
+			v.Set(key, strings.TrimRight(string(secret), "\r\n"))
+		}
+	}
+
+	if err = v.Unmarshal(&config); err != nil {
+		return config, err
+	}
+
+	if len(config.ClientOrigin) != 0 && !config.AllowsOrigin(config.ClientOrigin) {
+		config.CORSOrigins = append(config.CORSOrigins, config.ClientOrigin)
+	}
+
+	if err = config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return config, nil
+}
+
+// configKeys returns the mapstructure keys of a config struct, including the ones
+// of squashed embedded structs.
+func configKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if field.Anonymous && strings.Contains(tag, "squash") {
+			keys = append(keys, configKeys(field.Type)...)
+		} else if len(tag) != 0 {
+			keys = append(keys, tag)
+		}
+	}
+	return keys
+}
+
+// Watch reloads the config when the profile file loaded last changes and
+// stores the new Settings in live. Anything else needs a restart, changes to
+// it are only logged. A reload that fails to load or validate is ignored,
+// the running settings stay.
+func (l *Loader) Watch(current Config, live *Live) {
+	if len(l.file) == 0 {
+		log.Println("no config file, settings will not be reloaded")
+		return
+	}
+
+	var mu sync.Mutex
+	v := viper.New()
+	v.SetConfigFile(l.file)
+	v.OnConfigChange(func(event fsnotify.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		config, err := l.Load()
+		if err != nil {
+			log.Printf("config reload of %s rejected: %v", l.file, err)
+			return
+		}
+
+		live.Store(config.Settings)
+		log.Printf("config reloaded from %s", l.file)
+
+		config.Settings, current.Settings = Settings{}, Settings{}
+		if !reflect.DeepEqual(config, current) {
+			log.Println("config changes other than rate limits, CORS origins and feature flags apply after a restart")
+		}
+	})
+	v.WatchConfig()
+}
@@ -0,0 +1,174 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	private, public := testKeys(t)
+
+	// what every config needs and has no default for
+	required := map[string]string{
+		"DB_HOST":                   "localhost",
+		"DB_USER":                   "postgres",
+		"DB_NAME":                   "realworld",
+		"DB_PORT":                   "5432",
+		"PORT":                      "8000",
+		"ACCESS_TOKEN_PRIVATE_KEY":  private,
+		"ACCESS_TOKEN_PUBLIC_KEY":   public,
+		"REFRESH_TOKEN_PRIVATE_KEY": private,
+		"REFRESH_TOKEN_PUBLIC_KEY":  public,
+		"ACCESS_TOKEN_EXPIRED_IN":   "15m",
+		"REFRESH_TOKEN_EXPIRED_IN":  "1h",
+		"ACCESS_TOKEN_MAXAGE":       "15",
+		"REFRESH_TOKEN_MAXAGE":      "60",
+	}
+
+	tests := []struct {
+		name string
+		// files written to the config directory, {dir} in env values is
+		// replaced by its path
+		files map[string]string
+		env   map[string]string
+		check func(t *testing.T, config Config)
+		err   string
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, config Config) {
+				if config.Env != EnvDev || config.ServerReadTimeout != 15*time.Second || config.StorageBackend != "local" {
+					t.Errorf("got env %q, read timeout %s, storage %q, want the defaults", config.Env, config.ServerReadTimeout, config.StorageBackend)
+				}
+				if !config.FeatureSearch || config.RateLimit != 0 {
+					t.Errorf("got search %t, rate limit %v, want the defaults", config.FeatureSearch, config.RateLimit)
+				}
+			},
+		},
+		{
+			name:  "yaml profile overrides defaults",
+			files: map[string]string{"dev.yaml": "RATE_LIMIT: 5\nRATE_LIMIT_BURST: 10\nSTORAGE_LOCAL_DIR: media\n"},
+			check: func(t *testing.T, config Config) {
+				if config.RateLimit != 5 || config.RateLimitBurst != 10 || config.StorageLocalDir != "media" {
+					t.Errorf("got rate limit %v/%d, storage dir %q, want the profile's", config.RateLimit, config.RateLimitBurst, config.StorageLocalDir)
+				}
+			},
+		},
+		{
+			name:  "environment overrides profile",
+			files: map[string]string{"dev.env": "MEDIA_BASE_URL=/files\nDB_NAME=from_file\n"},
+			env:   map[string]string{"DB_NAME": "from_env"},
+			check: func(t *testing.T, config Config) {
+				if config.MediaBaseURL != "/files" || config.DBName != "from_env" {
+					t.Errorf("got media URL %q, db %q, want /files and from_env", config.MediaBaseURL, config.DBName)
+				}
+			},
+		},
+		{
+			name: "profile named by APP_ENV",
+			files: map[string]string{
+				"dev.yaml":  "MARKDOWN_CACHE_SIZE: 1\n",
+				"test.toml": "MARKDOWN_CACHE_SIZE = 2\n",
+			},
+			env: map[string]string{"APP_ENV": EnvTest},
+			check: func(t *testing.T, config Config) {
+				if config.Env != EnvTest || config.MarkdownCacheSize != 2 {
+					t.Errorf("got env %q, cache size %d, want test and 2", config.Env, config.MarkdownCacheSize)
+				}
+			},
+		},
+		{
+			name:  "file secret overrides environment",
+			files: map[string]string{"db_password": "s3cret\n"},
+			env: map[string]string{
+				"DB_PASSWORD":      "from_env",
+				"DB_PASSWORD_FILE": "{dir}/db_password",
+			},
+			check: func(t *testing.T, config Config) {
+				if config.DBUserPassword != "s3cret" {
+					t.Errorf("got password %q, want the file's without the newline", config.DBUserPassword)
+				}
+			},
+		},
+		{
+			name:  "file secret for a key",
+			files: map[string]string{"access_key": private + "\r\n"},
+			env: map[string]string{
+				"ACCESS_TOKEN_PRIVATE_KEY":      "",
+				"ACCESS_TOKEN_PRIVATE_KEY_FILE": "{dir}/access_key",
+			},
+			check: func(t *testing.T, config Config) {
+				if config.AccessTokenPrivateKey != private {
+					t.Errorf("got access token private key %q, want the file's", config.AccessTokenPrivateKey)
+				}
+			},
+		},
+		{
+			name: "missing file secret",
+			env:  map[string]string{"DB_PASSWORD_FILE": "{dir}/missing"},
+			err:  "DB_PASSWORD_FILE: ",
+		},
+		{
+			name: "client origin joins CORS origins",
+			env:  map[string]string{"CLIENT_ORIGIN": "https://example.com"},
+			check: func(t *testing.T, config Config) {
+				want := []string{"http://localhost:3000", "https://example.com"}
+				if strings.Join(config.CORSOrigins, " ") != strings.Join(want, " ") {
+					t.Errorf("got CORS origins %v, want %v", config.CORSOrigins, want)
+				}
+			},
+		},
+		{
+			name: "client origin already allowed",
+			env:  map[string]string{"CLIENT_ORIGIN": "http://localhost:3000"},
+			check: func(t *testing.T, config Config) {
+				if len(config.CORSOrigins) != 1 {
+					t.Errorf("got CORS origins %v, want http://localhost:3000 once", config.CORSOrigins)
+				}
+			},
+		},
+		{
+			name: "invalid config",
+			env:  map[string]string{"DB_HOST": "", "RATE_LIMIT": "5"},
+			err:  "invalid config:\nDB_HOST: is required\nRATE_LIMIT_BURST: must be at least 1",
+		},
+		{
+			name:  "unreadable profile",
+			files: map[string]string{"dev.yaml": "RATE_LIMIT: [\n"},
+			err:   "could not read ",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			t.Setenv("APP_ENV", "")
+			for key, value := range required {
+				t.Setenv(key, value)
+			}
+			for key, value := range test.env {
+				t.Setenv(key, strings.ReplaceAll(value, "{dir}", dir))
+			}
+
+			config, err := NewLoader(dir).Load()
+			if len(test.err) != 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("Load() error = %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			test.check(t, config)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package configs
+
+import (
+	"sync/atomic"
+)
+
+// Settings are the parts of the config that are safe to change while the
+// server runs. They are reloaded when the config file changes.
+type Settings struct {
+	CORSOrigins []string `mapstructure:"CORS_ORIGINS"`
+
+	// RateLimit is the number of requests per second allowed per client,
+	// 0 turns the limit off
+	RateLimit      float64 `mapstructure:"RATE_LIMIT"`
+	RateLimitBurst int     `mapstructure:"RATE_LIMIT_BURST"`
+
+	FeatureSearch  bool `mapstructure:"FEATURE_SEARCH"`
+	FeatureUploads bool `mapstructure:"FEATURE_UPLOADS"`
+}
+
+// AllowsOrigin reports whether origin may make cross-origin requests.
+func (s *Settings) AllowsOrigin(origin string) bool {
+	for _, allowed := range s.CORSOrigins {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// Live holds the current Settings. Readers get a snapshot that is never
+// modified, a reload swaps in a new one.
+type Live struct {
+	settings atomic.Pointer[Settings]
+}
+
+func NewLive(settings Settings) *Live {
+	live := &Live{}
+	live.Store(settings)
+	return live
+}
+
+func (l *Live) Load() *Settings {
+	return l.settings.Load()
+}
+
+func (l *Live) Store(settings Settings) {
+	l.settings.Store(&settings)
+}
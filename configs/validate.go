@@ -0,0 +1,175 @@
+package configs
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// problems collects what is wrong with a config, one error per key.
+type problems []error
+
+func (p *problems) add(key string, format string, args ...any) {
+	*p = append(*p, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+}
+
+func (p *problems) required(key string, value string) {
+	if len(value) == 0 {
+		p.add(key, "is required")
+	}
+}
+
+func (p *problems) oneOf(key string, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	p.add(key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+func (p *problems) positive(key string, value time.Duration) {
+	if value <= 0 {
+		p.add(key, "must be a positive duration, got %s", value)
+	}
+}
+
+// Validate reports every invalid field at once.
+func (config *Config) Validate() error {
+	var p problems
+
+	p.oneOf("APP_ENV", config.Env, EnvDev, EnvTest, EnvProd)
+
+	p.required("DB_HOST", config.DBHost)
+	p.required("DB_USER", config.DBUserName)
+	p.required("DB_NAME", config.DBName)
+	p.required("DB_PORT", config.DBPort)
+	p.required("PORT", config.ServerPort)
+
+	p.positive("SERVER_READ_TIMEOUT", config.ServerReadTimeout)
+	p.positive("SERVER_READ_HEADER_TIMEOUT", config.ServerReadHeaderTimeout)
+	p.positive("SERVER_WRITE_TIMEOUT", config.ServerWriteTimeout)
+	p.positive("SERVER_IDLE_TIMEOUT", config.ServerIdleTimeout)
+	p.positive("SHUTDOWN_TIMEOUT", config.ShutdownTimeout)
+	if config.ServerMaxHeaderBytes <= 0 {
+		p.add("SERVER_MAX_HEADER_BYTES", "must be positive")
+	}
+
+	if (len(config.TLSCertFile) == 0) != (len(config.TLSKeyFile) == 0) {
+		p.add("TLS_CERT_FILE", "must be set together with TLS_KEY_FILE")
+	}
+	if len(config.TLSRedirectPort) != 0 && len(config.TLSCertFile) == 0 {
+		p.add("TLS_REDIRECT_PORT", "needs TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	for _, proxy := range config.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			p.add("TRUSTED_PROXIES", "%q is not an IP or a CIDR range", proxy)
+		}
+	}
+
+	// 0 is allowed, it turns the deadline off
+	if config.DBQueryTimeout < 0 {
+		p.add("DB_QUERY_TIMEOUT", "must not be negative")
+	}
+	if config.DBMaxOpenConns <= 0 {
+		p.add("DB_MAX_OPEN_CONNS", "must be positive")
+	}
+	if config.DBMaxIdleConns < 0 || config.DBMaxIdleConns > config.DBMaxOpenConns {
+		p.add("DB_MAX_IDLE_CONNS", "must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+	p.positive("DB_CONN_MAX_LIFETIME", config.DBConnMaxLifetime)
+	p.positive("DB_CONN_MAX_IDLE_TIME", config.DBConnMaxIdleTime)
+
+	p.oneOf("REGISTRATION_MODE", config.RegistrationMode, RegistrationModeOpen, RegistrationModeInvite, RegistrationModeApproval)
+	p.oneOf("ACCOUNT_DELETION_POLICY", config.AccountDeletionPolicy, AccountDeletionCascade, AccountDeletionAnonymize)
+
+	p.oneOf("STORAGE_BACKEND", config.StorageBackend, "local", "s3")
+	switch config.StorageBackend {
+	case "local":
+		p.required("STORAGE_LOCAL_DIR", config.StorageLocalDir)
+	case "s3":
+		p.required("S3_REGION", config.S3Region)
+		p.required("S3_BUCKET", config.S3Bucket)
+	}
+	if config.UploadMaxSize <= 0 {
+		p.add("UPLOAD_MAX_SIZE", "must be positive")
+	}
+	if config.MarkdownCacheSize < 0 {
+		p.add("MARKDOWN_CACHE_SIZE", "must not be negative")
+	}
+
+	p.positive("PUBLISH_SCHEDULER_INTERVAL", config.PublishSchedulerInterval)
+	p.positive("TRASH_RETENTION", config.TrashRetention)
+	p.positive("TRASH_PURGE_INTERVAL", config.TrashPurgeInterval)
+	p.positive("COUNTER_RECONCILE_INTERVAL", config.CounterReconcileInterval)
+
+	p.rsaKey("ACCESS_TOKEN_PRIVATE_KEY", config.AccessTokenPrivateKey, true)
+	p.rsaKey("ACCESS_TOKEN_PUBLIC_KEY", config.AccessTokenPublicKey, false)
+	p.rsaKey("REFRESH_TOKEN_PRIVATE_KEY", config.RefreshTokenPrivateKey, true)
+	p.rsaKey("REFRESH_TOKEN_PUBLIC_KEY", config.RefreshTokenPublicKey, false)
+	p.positive("ACCESS_TOKEN_EXPIRED_IN", config.AccessTokenExpiresIn)
+	p.positive("REFRESH_TOKEN_EXPIRED_IN", config.RefreshTokenExpiresIn)
+	if config.AccessTokenMaxAge <= 0 {
+		p.add("ACCESS_TOKEN_MAXAGE", "must be positive")
+	}
+	if config.RefreshTokenMaxAge <= 0 {
+		p.add("REFRESH_TOKEN_MAXAGE", "must be positive")
+	}
+
+	p = append(p, config.Settings.Validate())
+	return errors.Join(p...)
+}
+
+// rsaKey checks value is a base64 encoded PEM RSA key, the way the token
+// helpers read them.
+func (p *problems) rsaKey(key string, value string, private bool) {
+	if len(value) == 0 {
+		p.add(key, "is required")
+		return
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		p.add(key, "is not base64 encoded")
+		return
+	}
+
+	if private {
+		_, err = jwt.ParseRSAPrivateKeyFromPEM(decoded)
+	} else {
+		_, err = jwt.ParseRSAPublicKeyFromPEM(decoded)
+	}
+	if err != nil {
+		p.add(key, "is not a PEM encoded RSA key: %v", err)
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (s *Settings) Validate() error {
+	var p problems
+
+	if len(s.CORSOrigins) == 0 {
+		p.add("CORS_ORIGINS", "needs at least one origin")
+	}
+	for _, origin := range s.CORSOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 || len(u.Path) != 0 {
+			p.add("CORS_ORIGINS", "%q is not an origin like https://example.com", origin)
+		}
+	}
+
+	if s.RateLimit < 0 {
+		p.add("RATE_LIMIT", "must not be negative")
+	}
+	if s.RateLimit > 0 && s.RateLimitBurst < 1 {
+		p.add("RATE_LIMIT_BURST", "must be at least 1")
+	}
+
+	return errors.Join(p...)
+}
@@ -0,0 +1,146 @@
+package configs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeys returns a base64 encoded PEM RSA key pair, the way the token
+// settings hold them.
+func testKeys(tb testing.TB) (private, public string) {
+	tb.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	private = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	public = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	return private, public
+}
+
+func validConfig(private, public string) Config {
+	return Config{
+		Env:                      EnvDev,
+		DBHost:                   "localhost",
+		DBUserName:               "postgres",
+		DBName:                   "realworld",
+		DBPort:                   "5432",
+		ServerPort:               "8000",
+		ServerReadTimeout:        15 * time.Second,
+		ServerReadHeaderTimeout:  5 * time.Second,
+		ServerWriteTimeout:       30 * time.Second,
+		ServerIdleTimeout:        2 * time.Minute,
+		ServerMaxHeaderBytes:     1 << 20,
+		ShutdownTimeout:          30 * time.Second,
+		DBQueryTimeout:           5 * time.Second,
+		DBMaxOpenConns:           25,
+		DBMaxIdleConns:           5,
+		DBConnMaxLifetime:        30 * time.Minute,
+		DBConnMaxIdleTime:        5 * time.Minute,
+		RegistrationMode:         RegistrationModeOpen,
+		AccountDeletionPolicy:    AccountDeletionAnonymize,
+		StorageBackend:           "local",
+		StorageLocalDir:          "uploads",
+		UploadMaxSize:            5 << 20,
+		MarkdownCacheSize:        1000,
+		PublishSchedulerInterval: time.Minute,
+		TrashRetention:           30 * 24 * time.Hour,
+		TrashPurgeInterval:       time.Hour,
+		CounterReconcileInterval: 24 * time.Hour,
+		AccessTokenPrivateKey:    private,
+		AccessTokenPublicKey:     public,
+		RefreshTokenPrivateKey:   private,
+		RefreshTokenPublicKey:    public,
+		AccessTokenExpiresIn:     15 * time.Minute,
+		RefreshTokenExpiresIn:    time.Hour,
+		AccessTokenMaxAge:        15,
+		RefreshTokenMaxAge:       60,
+		Settings: Settings{
+			CORSOrigins: []string{"http://localhost:3000"},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	private, public := testKeys(t)
+
+	tests := []struct {
+		name   string
+		change func(*Config)
+		// the keys reported, none for a valid config
+		problems []string
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"unknown env", func(c *Config) { c.Env = "staging" }, []string{"APP_ENV"}},
+		{"every problem at once", func(c *Config) {
+			c.DBHost = ""
+			c.ServerPort = ""
+			c.ShutdownTimeout = 0
+		}, []string{"DB_HOST", "PORT", "SHUTDOWN_TIMEOUT"}},
+		{"no query deadline", func(c *Config) { c.DBQueryTimeout = 0 }, nil},
+		{"negative query deadline", func(c *Config) { c.DBQueryTimeout = -time.Second }, []string{"DB_QUERY_TIMEOUT"}},
+		{"more idle than open connections", func(c *Config) { c.DBMaxIdleConns = 30 }, []string{"DB_MAX_IDLE_CONNS"}},
+		{"certificate without key", func(c *Config) { c.TLSCertFile = "cert.pem" }, []string{"TLS_CERT_FILE"}},
+		{"redirect without TLS", func(c *Config) { c.TLSRedirectPort = "80" }, []string{"TLS_REDIRECT_PORT"}},
+		{"trusted proxies", func(c *Config) { c.TrustedProxies = []string{"10.0.0.1", "10.0.0.0/8", "::1"} }, nil},
+		{"trusted proxy hostname", func(c *Config) { c.TrustedProxies = []string{"proxy.internal"} }, []string{"TRUSTED_PROXIES"}},
+		{"unknown registration mode", func(c *Config) { c.RegistrationMode = "closed" }, []string{"REGISTRATION_MODE"}},
+		{"unknown storage backend", func(c *Config) { c.StorageBackend = "gcs" }, []string{"STORAGE_BACKEND"}},
+		{"s3 without bucket", func(c *Config) {
+			c.StorageBackend = "s3"
+			c.S3Region = "eu-west-1"
+		}, []string{"S3_BUCKET"}},
+		{"negative markdown cache", func(c *Config) { c.MarkdownCacheSize = -1 }, []string{"MARKDOWN_CACHE_SIZE"}},
+		{"missing key", func(c *Config) { c.AccessTokenPrivateKey = "" }, []string{"ACCESS_TOKEN_PRIVATE_KEY"}},
+		{"key not base64", func(c *Config) { c.AccessTokenPublicKey = "not base64!" }, []string{"ACCESS_TOKEN_PUBLIC_KEY"}},
+		{"public key for private key", func(c *Config) { c.RefreshTokenPrivateKey = public }, []string{"REFRESH_TOKEN_PRIVATE_KEY"}},
+		{"no CORS origin", func(c *Config) { c.CORSOrigins = nil }, []string{"CORS_ORIGINS"}},
+		{"CORS origin with path", func(c *Config) { c.CORSOrigins = []string{"https://example.com/app"} }, []string{"CORS_ORIGINS"}},
+		{"CORS origin without scheme", func(c *Config) { c.CORSOrigins = []string{"example.com"} }, []string{"CORS_ORIGINS"}},
+		{"negative rate limit", func(c *Config) { c.RateLimit = -1 }, []string{"RATE_LIMIT"}},
+		{"rate limit without burst", func(c *Config) { c.RateLimit = 5 }, []string{"RATE_LIMIT_BURST"}},
+		{"rate limit with burst", func(c *Config) {
+			c.RateLimit = 5
+			c.RateLimitBurst = 10
+		}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := validConfig(private, public)
+			test.change(&config)
+
+			err := config.Validate()
+			if len(test.problems) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want problems with %v", test.problems)
+			}
+
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(test.problems) {
+				t.Errorf("Validate() reported %d problems, want %d:\n%v", len(lines), len(test.problems), err)
+			}
+			for _, key := range test.problems {
+				if !strings.Contains(err.Error(), key+": ") {
+					t.Errorf("Validate() does not report %s:\n%v", key, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "user_follow";
+DROP TABLE IF EXISTS "user_likes";
+DROP TABLE IF EXISTS "comments";
+DROP TABLE IF EXISTS "article_tag";
+DROP TABLE IF EXISTS "tags";
+DROP TABLE IF EXISTS "articles";
+DROP TABLE IF EXISTS "users";
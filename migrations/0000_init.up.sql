@@ -0,0 +1,57 @@
+-- the schema the application started from; IF NOT EXISTS lets databases
+-- created before migrations were tracked adopt it as their baseline
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" serial PRIMARY KEY,
+    "username" text NOT NULL UNIQUE,
+    "email" text NOT NULL UNIQUE,
+    "password" text NOT NULL,
+    "bio" text,
+    "image" text,
+    "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "articles" (
+    "id" serial PRIMARY KEY,
+    "id_author" integer NOT NULL REFERENCES "users" ("id"),
+    "slug" text NOT NULL UNIQUE,
+    "title" text NOT NULL,
+    "description" text,
+    "body" text NOT NULL,
+    "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "tags" (
+    "id" serial PRIMARY KEY,
+    "name" text NOT NULL UNIQUE,
+    "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "article_tag" (
+    "id_article" integer NOT NULL REFERENCES "articles" ("id") ON DELETE CASCADE,
+    "id_tag" integer NOT NULL REFERENCES "tags" ("id") ON DELETE CASCADE,
+    PRIMARY KEY ("id_article", "id_tag")
+);
+
+CREATE TABLE IF NOT EXISTS "comments" (
+    "id" serial PRIMARY KEY,
+    "id_author" integer NOT NULL REFERENCES "users" ("id"),
+    "id_article" integer NOT NULL REFERENCES "articles" ("id") ON DELETE CASCADE,
+    "body" text NOT NULL,
+    "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "user_likes" (
+    "id_user" integer NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+    "id_article" integer NOT NULL REFERENCES "articles" ("id") ON DELETE CASCADE,
+    PRIMARY KEY ("id_user", "id_article")
+);
+
+CREATE TABLE IF NOT EXISTS "user_follow" (
+    "id_user_a" integer NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+    "id_user_b" integer NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+    PRIMARY KEY ("id_user_a", "id_user_b")
+);
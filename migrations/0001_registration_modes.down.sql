@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "users_status_idx";
+DROP TABLE IF EXISTS "invite_codes";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "status";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
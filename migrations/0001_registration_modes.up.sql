@@ -0,0 +1,15 @@
+ALTER TABLE "users" ADD COLUMN "role" text NOT NULL DEFAULT 'user';
+ALTER TABLE "users" ADD COLUMN "status" text NOT NULL DEFAULT 'active';
+
+CREATE TABLE "invite_codes" (
+    "id" serial PRIMARY KEY,
+    "code" text NOT NULL UNIQUE,
+    "max_uses" integer NOT NULL DEFAULT 1,
+    "uses" integer NOT NULL DEFAULT 0,
+    "created_by" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+    "expires_at" timestamp with time zone,
+    "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "users_status_idx" ON "users" ("status");
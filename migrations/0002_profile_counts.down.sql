@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "articles_id_author_idx";
+DROP INDEX IF EXISTS "user_follow_id_user_a_created_at_idx";
+DROP INDEX IF EXISTS "user_follow_id_user_b_idx";
+ALTER TABLE "user_follow" DROP COLUMN IF EXISTS "created_at";
@@ -0,0 +1,5 @@
+ALTER TABLE "user_follow" ADD COLUMN "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX "user_follow_id_user_b_idx" ON "user_follow" ("id_user_b", "created_at");
+CREATE INDEX "user_follow_id_user_a_created_at_idx" ON "user_follow" ("id_user_a", "created_at");
+CREATE INDEX "articles_id_author_idx" ON "articles" ("id_author");
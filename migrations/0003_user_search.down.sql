@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "user_likes_id_user_idx";
+DROP INDEX IF EXISTS "article_tag_id_tag_idx";
+DROP INDEX IF EXISTS "users_bio_trgm_idx";
+DROP INDEX IF EXISTS "users_username_trgm_idx";
@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+CREATE INDEX "users_username_trgm_idx" ON "users" USING gin ("username" gin_trgm_ops);
+CREATE INDEX "users_bio_trgm_idx" ON "users" USING gin ("bio" gin_trgm_ops);
+CREATE INDEX "article_tag_id_tag_idx" ON "article_tag" ("id_tag");
+CREATE INDEX "user_likes_id_user_idx" ON "user_likes" ("id_user");
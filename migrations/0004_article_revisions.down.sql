@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "article_revisions";
+DROP FUNCTION IF EXISTS "article_revisions_immutable"();
@@ -0,0 +1,35 @@
+CREATE TABLE "article_revisions" (
+    "id" serial PRIMARY KEY,
+    "id_article" integer NOT NULL REFERENCES "articles" ("id") ON DELETE CASCADE,
+    "revision" integer NOT NULL,
+    "id_author" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+    "title" text NOT NULL,
+    "description" text,
+    "body" text NOT NULL,
+    "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE ("id_article", "revision")
+);
+
+-- revisions are immutable, only the author reference may be cleared when the
+-- account is deleted
+CREATE FUNCTION "article_revisions_immutable"() RETURNS trigger AS $$
+BEGIN
+    IF NEW."id_article" <> OLD."id_article"
+        OR NEW."revision" <> OLD."revision"
+        OR NEW."title" IS DISTINCT FROM OLD."title"
+        OR NEW."description" IS DISTINCT FROM OLD."description"
+        OR NEW."body" IS DISTINCT FROM OLD."body"
+        OR NEW."created_at" <> OLD."created_at" THEN
+        RAISE EXCEPTION 'article revisions are immutable';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "article_revisions_immutable"
+    BEFORE UPDATE ON "article_revisions"
+    FOR EACH ROW EXECUTE FUNCTION "article_revisions_immutable"();
+
+-- the current content of existing articles becomes their first revision
+INSERT INTO "article_revisions" ("id_article", "revision", "id_author", "title", "description", "body", "created_at")
+SELECT "id", 1, "id_author", "title", "description", "body", "updated_at" FROM "articles";
@@ -0,0 +1,9 @@
+ALTER TABLE "articles" ADD COLUMN "status" text NOT NULL DEFAULT 'published'
+    CHECK ("status" IN ('draft', 'scheduled', 'published', 'unpublished'));
+ALTER TABLE "articles" ADD COLUMN "publish_at" timestamp with time zone;
+
+-- existing articles were public from the moment they were written
+UPDATE "articles" SET "publish_at" = "created_at";
+
+CREATE INDEX "articles_status_publish_at_idx" ON "articles" ("status", "publish_at" DESC, "id" DESC);
+CREATE INDEX "articles_scheduled_idx" ON "articles" ("publish_at") WHERE "status" = 'scheduled';
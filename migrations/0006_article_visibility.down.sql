@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "articles_status_visibility_publish_at_idx";
+CREATE INDEX "articles_status_publish_at_idx" ON "articles" ("status", "publish_at" DESC, "id" DESC);
+
+ALTER TABLE "articles" DROP COLUMN IF EXISTS "visibility";
@@ -0,0 +1,5 @@
+ALTER TABLE "articles" ADD COLUMN "visibility" text NOT NULL DEFAULT 'public'
+    CHECK ("visibility" IN ('public', 'unlisted', 'followers'));
+
+DROP INDEX IF EXISTS "articles_status_publish_at_idx";
+CREATE INDEX "articles_status_visibility_publish_at_idx" ON "articles" ("status", "visibility", "publish_at" DESC, "id" DESC);
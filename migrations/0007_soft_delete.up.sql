@@ -0,0 +1,6 @@
+ALTER TABLE "articles" ADD COLUMN "deleted_at" timestamp with time zone;
+ALTER TABLE "comments" ADD COLUMN "deleted_at" timestamp with time zone;
+
+-- trash listings and the purge job only ever look at deleted rows
+CREATE INDEX "articles_deleted_at_idx" ON "articles" ("id_author", "deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "comments_deleted_at_idx" ON "comments" ("id_author", "deleted_at") WHERE "deleted_at" IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "articles_updated_at_idx";
+DROP INDEX IF EXISTS "comments_id_article_created_at_idx";
+DROP INDEX IF EXISTS "user_likes_id_article_created_at_idx";
+
+ALTER TABLE "user_likes" DROP COLUMN IF EXISTS "created_at";
@@ -0,0 +1,5 @@
+ALTER TABLE "user_likes" ADD COLUMN "created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX "user_likes_id_article_created_at_idx" ON "user_likes" ("id_article", "created_at");
+CREATE INDEX "comments_id_article_created_at_idx" ON "comments" ("id_article", "created_at");
+CREATE INDEX "articles_updated_at_idx" ON "articles" ("updated_at" DESC, "id" DESC);
@@ -0,0 +1,8 @@
+-- title weighs most, then description, then body
+ALTER TABLE "articles" ADD COLUMN "search_vector" tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce("title", '')), 'A') ||
+    setweight(to_tsvector('english', coalesce("description", '')), 'B') ||
+    setweight(to_tsvector('english', coalesce("body", '')), 'C')
+) STORED;
+
+CREATE INDEX "articles_search_vector_idx" ON "articles" USING GIN ("search_vector");
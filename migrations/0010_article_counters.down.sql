@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "articles_comments_count_idx";
+DROP INDEX IF EXISTS "articles_favorites_count_idx";
+ALTER TABLE "articles" DROP COLUMN IF EXISTS "comments_count";
+ALTER TABLE "articles" DROP COLUMN IF EXISTS "favorites_count";
@@ -0,0 +1,9 @@
+ALTER TABLE "articles" ADD COLUMN "favorites_count" integer NOT NULL DEFAULT 0;
+ALTER TABLE "articles" ADD COLUMN "comments_count" integer NOT NULL DEFAULT 0;
+
+UPDATE "articles" AS a SET
+  "favorites_count" = (SELECT COUNT(*) FROM "user_likes" AS l WHERE l."id_article" = a."id"),
+  "comments_count" = (SELECT COUNT(*) FROM "comments" AS c WHERE c."id_article" = a."id" AND c."deleted_at" IS NULL);
+
+CREATE INDEX "articles_favorites_count_idx" ON "articles" ("favorites_count" DESC, "id" DESC);
+CREATE INDEX "articles_comments_count_idx" ON "articles" ("comments_count" DESC, "id" DESC);
@@ -0,0 +1,2 @@
+DELETE FROM "comments" WHERE "id_author" IS NULL;
+ALTER TABLE "comments" ALTER COLUMN "id_author" SET NOT NULL;
@@ -0,0 +1,3 @@
+-- purged comments stay behind as tombstones without a body or an author, so
+-- the threads they were part of still read in order
+ALTER TABLE "comments" ALTER COLUMN "id_author" DROP NOT NULL;
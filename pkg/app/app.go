@@ -0,0 +1,129 @@
+// Package app wires the API together from a config and its dependencies.
+// Nothing is global, so several instances can live in one process.
+package app
+
+import (
+	"net/http"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/controllers"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/jobs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/markdown"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/middlewares"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/routes"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/storage"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// App is the API as an http.Handler together with its background jobs.
+type App struct {
+	Config  *configs.Config
+	DB      *gorm.DB
+	Storage storage.Storage
+
+	// Settings starts out as Config.Settings and follows config reloads
+	Settings *configs.Live
+
+	PublishScheduler  *jobs.PublishScheduler
+	TrashPurger       *jobs.TrashPurger
+	CounterReconciler *jobs.CounterReconciler
+
+	engine *gin.Engine
+}
+
+func New(config *configs.Config, DB *gorm.DB, mediaStorage storage.Storage) (*App, error) {
+	settings := configs.NewLive(config.Settings)
+	requireUser := middlewares.DeserializeUser(DB, config)
+	optionalUser := middlewares.OptionalUser(DB, config)
+	requireSearch := middlewares.RequireFeature(settings, func(s *configs.Settings) bool { return s.FeatureSearch })
+	requireUploads := middlewares.RequireFeature(settings, func(s *configs.Settings) bool { return s.FeatureUploads })
+
+	tagController := controllers.NewTagController(DB)
+	tagRouteController := routes.NewTagRouteController(tagController)
+
+	userController := controllers.NewUserController(DB, mediaStorage, config)
+	userRouteController := routes.NewUserRouteController(userController, requireUser)
+
+	articleController := controllers.NewArticleController(DB, markdown.NewRenderer(config.MarkdownCacheSize))
+	commentController := controllers.NewCommentController(DB)
+	articleRouteController := routes.NewArticleRouteController(articleController, commentController, requireSearch, requireUser, optionalUser)
+
+	adminController := controllers.NewAdminController(DB)
+	adminRouteController := routes.NewAdminRouteController(adminController, requireUser)
+
+	uploadController := controllers.NewUploadController(DB, mediaStorage, config)
+	uploadRouteController := routes.NewUploadRouteController(uploadController, requireUser)
+
+	trashController := controllers.NewTrashController(DB, config.TrashRetention)
+	trashRouteController := routes.NewTrashRouteController(trashController, requireUser)
+
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOriginFunc = func(origin string) bool {
+		return settings.Load().AllowsOrigin(origin)
+	}
+	corsConfig.AllowCredentials = true
+
+	engine := gin.New()
+	// the rate limit keys on the client IP, only proxies may tell it
+	if err := engine.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, err
+	}
+	engine.Use(gin.Logger())
+	engine.Use(cors.New(corsConfig))
+	engine.Use(middlewares.RenderErrors())
+	// inside RenderErrors, so a panic is answered like any other error
+	engine.Use(middlewares.RecoverPanics())
+	engine.Use(middlewares.RateLimit(settings))
+
+	router := engine.Group("/api")
+	router.GET("/healthchecker", func(ctx *gin.Context) {
+		message := "Welcome to Realworld Project"
+		ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": message})
+	})
+
+	tagRouteController.TagRoute(router)
+	userRouteController.UserRoute(router)
+	userRouteController.SingleUserRoute(router)
+	userRouteController.ProfileRoute(router)
+	articleRouteController.ArticleRoute(router)
+	adminRouteController.AdminRoute(router)
+	uploadRouteController.UploadRoute(router.Group("", requireUploads))
+	trashRouteController.TrashRoute(router)
+	uploadRouteController.MediaRoute(&engine.RouterGroup)
+
+	return &App{
+		Config:  config,
+		DB:      DB,
+		Storage: mediaStorage,
+
+		Settings: settings,
+
+		PublishScheduler:  jobs.NewPublishScheduler(DB, config.PublishSchedulerInterval),
+		TrashPurger:       jobs.NewTrashPurger(DB, config.TrashRetention, config.TrashPurgeInterval),
+		CounterReconciler: jobs.NewCounterReconciler(DB, config.CounterReconcileInterval),
+
+		engine: engine,
+	}, nil
+}
+
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.engine.ServeHTTP(w, r)
+}
+
+// StartJobs runs the background jobs until StopJobs is called.
+func (a *App) StartJobs() {
+	a.PublishScheduler.Start()
+	a.TrashPurger.Start()
+	a.CounterReconciler.Start()
+}
+
+// StopJobs stops the background jobs one after the other, waiting for
+// running ones to finish: publishing first, so nothing becomes visible
+// during shutdown, then the purge and the counter repair.
+func (a *App) StopJobs() {
+	a.PublishScheduler.Stop()
+	a.TrashPurger.Stop()
+	a.CounterReconciler.Stop()
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestApp serves an App with its own media storage over httptest. The
+// database is never reached by the requests made here, so nothing listens
+// behind it.
+func newTestApp(t *testing.T, config configs.Config) (*App, *httptest.Server) {
+	t.Helper()
+
+	DB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=db.invalid"}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaStorage, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.MarkdownCacheSize = 10
+	application, err := New(&config, DB, mediaStorage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(application)
+	t.Cleanup(server.Close)
+	return application, server
+}
+
+func get(t *testing.T, server *httptest.Server, path string, header http.Header) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestAppsSideBySide(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	a, serverA := newTestApp(t, configs.Config{Settings: configs.Settings{
+		CORSOrigins:    []string{"https://a.example"},
+		RateLimit:      1,
+		RateLimitBurst: 3,
+		FeatureSearch:  true,
+	}})
+	_, serverB := newTestApp(t, configs.Config{Settings: configs.Settings{CORSOrigins: []string{"https://b.example"}}})
+
+	for _, server := range []*httptest.Server{serverA, serverB} {
+		if resp := get(t, server, "/api/healthchecker", nil); resp.StatusCode != http.StatusOK {
+			t.Errorf("%s healthchecker = %d", server.URL, resp.StatusCode)
+		}
+	}
+
+	// each app only lets its own origin in
+	origin := http.Header{"Origin": {"https://a.example"}}
+	if resp := get(t, serverB, "/api/healthchecker", origin); resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Error("B allowed A's origin")
+	}
+	if resp := get(t, serverB, "/api/healthchecker", http.Header{"Origin": {"https://b.example"}}); resp.Header.Get("Access-Control-Allow-Origin") != "https://b.example" {
+		t.Error("B refused its own origin")
+	}
+
+	// search is only turned on in A, where a missing q is the next problem
+	if resp := get(t, serverA, "/api/articles/search", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("A search without q = %d, want 400", resp.StatusCode)
+	}
+	if resp := get(t, serverB, "/api/articles/search", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("B search = %d, want 404", resp.StatusCode)
+	}
+
+	// both answer in the RealWorld errors shape
+	resp := get(t, serverB, "/api/user", nil)
+	var body struct {
+		Errors struct {
+			Body []string `json:"body"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || resp.StatusCode != http.StatusUnauthorized || len(body.Errors.Body) == 0 {
+		t.Errorf("B /api/user = %d, %+v, %v", resp.StatusCode, body, err)
+	}
+
+	// media only lives in the storage of the app it was put in
+	if err := a.Storage.Put(context.Background(), "images/1/a.png", []byte("png"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if resp := get(t, serverA, "/media/images/1/a.png", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("A media = %d, want 200", resp.StatusCode)
+	}
+	if resp := get(t, serverB, "/media/images/1/a.png", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("B media = %d, want 404", resp.StatusCode)
+	}
+
+	// A has used up its burst, B has no limit at all
+	if resp := get(t, serverA, "/api/healthchecker", nil); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("A after its burst = %d, want 429", resp.StatusCode)
+	}
+	for i := 0; i < 5; i++ {
+		if resp := get(t, serverB, "/api/healthchecker", nil); resp.StatusCode != http.StatusOK {
+			t.Errorf("B request %d = %d", i, resp.StatusCode)
+		}
+	}
+
+	// settings reloaded in one app leave the other alone
+	a.Settings.Store(configs.Settings{CORSOrigins: []string{"https://b.example"}})
+	if resp := get(t, serverA, "/api/healthchecker", http.Header{"Origin": {"https://b.example"}}); resp.Header.Get("Access-Control-Allow-Origin") != "https://b.example" {
+		t.Error("A did not pick up its new origins")
+	}
+	if resp := get(t, serverB, "/api/healthchecker", origin); resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Error("B picked up A's settings")
+	}
+}
+
+func TestForwardedForIsOnlyBelievedFromTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	settings := configs.Settings{RateLimit: 1, RateLimitBurst: 1}
+	_, direct := newTestApp(t, configs.Config{Settings: settings})
+	_, proxied := newTestApp(t, configs.Config{Settings: settings, TrustedProxies: []string{"127.0.0.1/32"}})
+
+	for i, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+		header := http.Header{"X-Forwarded-For": {forwardedFor}}
+
+		// a client can't get a fresh allowance by making up addresses
+		want := http.StatusOK
+		if i > 0 {
+			want = http.StatusTooManyRequests
+		}
+		if resp := get(t, direct, "/api/healthchecker", header); resp.StatusCode != want {
+			t.Errorf("direct request %d = %d, want %d", i, resp.StatusCode, want)
+		}
+
+		// behind a trusted proxy every forwarded client has its own
+		if resp := get(t, proxied, "/api/healthchecker", header); resp.StatusCode != http.StatusOK {
+			t.Errorf("proxied request %d = %d, want 200", i, resp.StatusCode)
+		}
+	}
+}
+
+func TestNewRejectsInvalidTrustedProxies(t *testing.T) {
+	mediaStorage, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &configs.Config{TrustedProxies: []string{"not-an-ip"}}
+	if _, err := New(config, nil, mediaStorage); err == nil {
+		t.Error("New accepted an invalid trusted proxy")
+	}
+}
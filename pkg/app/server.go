@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+)
+
+// TLSEnabled reports whether the app is served over HTTPS.
+func (a *App) TLSEnabled() bool {
+	return len(a.Config.TLSCertFile) != 0 && len(a.Config.TLSKeyFile) != 0
+}
+
+func (a *App) newServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       a.Config.ServerReadTimeout,
+		ReadHeaderTimeout: a.Config.ServerReadHeaderTimeout,
+		WriteTimeout:      a.Config.ServerWriteTimeout,
+		IdleTimeout:       a.Config.ServerIdleTimeout,
+		MaxHeaderBytes:    a.Config.ServerMaxHeaderBytes,
+	}
+}
+
+// Run serves the app and its background jobs until ctx is done. It then
+// stops taking connections, lets in-flight requests finish within the
+// shutdown timeout and stops the jobs once nothing can schedule work anymore.
+func (a *App) Run(ctx context.Context) error {
+	servers := []*http.Server{a.newServer(":"+a.Config.ServerPort, a)}
+	if a.TLSEnabled() && len(a.Config.TLSRedirectPort) != 0 {
+		servers = append(servers, a.newServer(":"+a.Config.TLSRedirectPort, http.HandlerFunc(a.redirectToHTTPS)))
+	}
+
+	failed := make(chan error, len(servers))
+	for i, server := range servers {
+		go func(server *http.Server, main bool) {
+			var err error
+			if main && a.TLSEnabled() {
+				err = server.ListenAndServeTLS(a.Config.TLSCertFile, a.Config.TLSKeyFile)
+			} else {
+				err = server.ListenAndServe()
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				failed <- err
+			}
+		}(server, i == 0)
+	}
+
+	a.StartJobs()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Println("shutting down, draining in-flight requests")
+	case err = <-failed:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.Config.ShutdownTimeout)
+	defer cancel()
+
+	for _, server := range servers {
+		if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+
+	a.StopJobs()
+	return err
+}
+
+// redirectToHTTPS sends plain HTTP requests to the same URL over HTTPS.
+func (a *App) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if a.Config.ServerPort != "443" {
+		host = net.JoinHostPort(host, a.Config.ServerPort)
+	}
+
+	target := "https://" + host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusPermanentRedirect)
+}
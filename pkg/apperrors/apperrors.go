@@ -0,0 +1,145 @@
+// Package apperrors defines the errors handlers report to clients. They are
+// rendered by middlewares.RenderErrors in the RealWorld errors shape.
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindForbidden
+	KindUnauthorized
+	KindTooLarge
+	KindUnsupportedMediaType
+	KindTooManyRequests
+	KindCanceled
+	KindTimeout
+)
+
+// StatusClientClosedRequest is the non-standard status logged for requests
+// the client gave up on.
+const StatusClientClosedRequest = 499
+
+// Postgres cancelled the query, here because of statement_timeout.
+const pgQueryCanceled = "57014"
+
+var statuses = map[Kind]int{
+	KindInternal:             http.StatusInternalServerError,
+	KindBadRequest:           http.StatusBadRequest,
+	KindValidation:           http.StatusUnprocessableEntity,
+	KindNotFound:             http.StatusNotFound,
+	KindConflict:             http.StatusConflict,
+	KindForbidden:            http.StatusForbidden,
+	KindUnauthorized:         http.StatusUnauthorized,
+	KindTooLarge:             http.StatusRequestEntityTooLarge,
+	KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	KindTooManyRequests:      http.StatusTooManyRequests,
+	KindCanceled:             StatusClientClosedRequest,
+	KindTimeout:              http.StatusServiceUnavailable,
+}
+
+// Error is an error meant for the client. Messages are shown as they are,
+// Err is the underlying cause and is only logged.
+type Error struct {
+	Kind     Kind
+	Messages []string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	message := strings.Join(e.Messages, "; ")
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status is the HTTP status the error is rendered with.
+func (e *Error) Status() int {
+	return statuses[e.Kind]
+}
+
+func BadRequest(message string) *Error {
+	return &Error{Kind: KindBadRequest, Messages: []string{message}}
+}
+
+// Validation reports every problem found with the request at once.
+func Validation(messages ...string) *Error {
+	return &Error{Kind: KindValidation, Messages: messages}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Messages: []string{message}}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Messages: []string{message}}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Messages: []string{message}}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Messages: []string{message}}
+}
+
+func TooLarge(message string) *Error {
+	return &Error{Kind: KindTooLarge, Messages: []string{message}}
+}
+
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Kind: KindUnsupportedMediaType, Messages: []string{message}}
+}
+
+func TooManyRequests(message string) *Error {
+	return &Error{Kind: KindTooManyRequests, Messages: []string{message}}
+}
+
+// Internal wraps an unexpected failure. Clients only learn that something
+// went wrong, err itself is logged. A query cut short because the client went
+// away or the deadline passed is reported as such instead.
+func Internal(err error) *Error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &Error{Kind: KindCanceled, Messages: []string{"request canceled"}, Err: err}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled:
+		return &Error{Kind: KindTimeout, Messages: []string{"the request took too long, try again later"}, Err: err}
+	}
+
+	return &Error{Kind: KindInternal, Messages: []string{"internal server error"}, Err: err}
+}
+
+// From returns err as an application error, treating anything else as
+// internal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// Abort stops the request chain and hands err over to the renderer.
+func Abort(ctx *gin.Context, err error) {
+	ctx.Abort()
+	_ = ctx.Error(err)
+}
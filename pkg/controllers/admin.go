@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AdminController struct {
+	DB *gorm.DB
+}
+
+func NewAdminController(DB *gorm.DB) AdminController {
+	return AdminController{DB}
+}
+
+func (adc *AdminController) CreateInviteCode(ctx *gin.Context) {
+	db := adc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	var payload models.InviteCodeCreateRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
+			return
+		}
+	}
+
+	// a single-use code unless told otherwise, 0 means unlimited uses
+	maxUses := int32(1)
+	if payload.Invite.MaxUses != nil {
+		maxUses = *payload.Invite.MaxUses
+	}
+
+	if maxUses < 0 {
+		apperrors.Abort(ctx, apperrors.Validation("maxUses must be 0 (unlimited) or greater"))
+		return
+	} else if payload.Invite.ExpiresAt != nil && payload.Invite.ExpiresAt.Before(time.Now()) {
+		apperrors.Abort(ctx, apperrors.Validation("expiresAt must be in the future"))
+		return
+	}
+
+	code, err := utils.GenerateInviteCode()
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	queryInsert := `INSERT INTO invite_codes (code, max_uses, created_by, expires_at) VALUES (?, ?, ?, ?) RETURNING *`
+
+	var invite models.InviteCode
+	process := db.Raw(queryInsert, code, maxUses, currentUser.ID, payload.Invite.ExpiresAt).Scan(&invite)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	inviteResponse := &models.InviteCodeResponseData{
+		Invite: &models.InviteCodeResponse{
+			Code:      invite.Code,
+			MaxUses:   invite.MaxUses,
+			Uses:      invite.Uses,
+			ExpiresAt: invite.ExpiresAt,
+			CreatedAt: invite.CreatedAt,
+		},
+	}
+
+	ctx.JSON(http.StatusCreated, inviteResponse)
+}
+
+func (adc *AdminController) GetInviteCodes(ctx *gin.Context) {
+	db := adc.DB.WithContext(ctx.Request.Context())
+
+	limit, offset, problems := parseOffsetPage(ctx, 20)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+
+	var invites []models.InviteCode
+	process := db.Raw(`SELECT * FROM invite_codes ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, limit, offset).Scan(&invites)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	total, err := countRows(db, `SELECT id FROM invite_codes`, nil, false)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	inviteResponseArray := make([]models.InviteCodeResponse, 0)
+	for _, invite := range invites {
+		inviteResponseArray = append(inviteResponseArray, models.InviteCodeResponse{
+			Code:      invite.Code,
+			MaxUses:   invite.MaxUses,
+			Uses:      invite.Uses,
+			ExpiresAt: invite.ExpiresAt,
+			CreatedAt: invite.CreatedAt,
+		})
+	}
+
+	response := pageMetadata(ctx, pagination{Limit: limit, Offset: offset, Count: len(inviteResponseArray), Total: &total})
+	response["invites"] = inviteResponseArray
+	response["invitesCount"] = total
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (adc *AdminController) DeleteInviteCode(ctx *gin.Context) {
+	db := adc.DB.WithContext(ctx.Request.Context())
+
+	code := ctx.Param("code")
+
+	process := db.Exec(`DELETE FROM invite_codes WHERE code = ?`, code)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	} else if process.RowsAffected == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("invite code not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (adc *AdminController) GetPendingUsers(ctx *gin.Context) {
+	db := adc.DB.WithContext(ctx.Request.Context())
+
+	limit, offset, problems := parseOffsetPage(ctx, 20)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+
+	pendingUsers := make([]models.PendingUser, 0)
+	process := db.Raw(`SELECT username, email, created_at FROM users WHERE status = ? ORDER BY created_at ASC, id ASC LIMIT ? OFFSET ?`, models.UserStatusPending, limit, offset).Scan(&pendingUsers)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	total, err := countRows(db, `SELECT id FROM users WHERE status = ?`, []interface{}{models.UserStatusPending}, false)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	response := pageMetadata(ctx, pagination{Limit: limit, Offset: offset, Count: len(pendingUsers), Total: &total})
+	response["users"] = pendingUsers
+	response["usersCount"] = total
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (adc *AdminController) ApproveUser(ctx *gin.Context) {
+	db := adc.DB.WithContext(ctx.Request.Context())
+
+	username := ctx.Param("username")
+
+	queryApprove := `UPDATE users SET status = ?, updated_at = ? WHERE username = ? AND status = ?`
+	process := db.Exec(queryApprove, models.UserStatusActive, time.Now(), username, models.UserStatusPending)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	} else if process.RowsAffected == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("pending user not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (adc *AdminController) RejectUser(ctx *gin.Context) {
+	db := adc.DB.WithContext(ctx.Request.Context())
+
+	username := ctx.Param("username")
+
+	process := db.Exec(`DELETE FROM users WHERE username = ? AND status = ?`, username, models.UserStatusPending)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	} else if process.RowsAffected == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("pending user not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -1,450 +1,548 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/markdown"
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 )
 
-type ArticleController struct {
-	DB *gorm.DB
+const queryInsertRevision = `
+	INSERT INTO article_revisions (id_article, revision, id_author, title, description, body, created_at)
+	SELECT ?, COALESCE(MAX(r.revision), 0) + 1, ?, ?, ?, ?, ?
+	FROM article_revisions AS r
+	WHERE r.id_article = ?`
+
+// articleVisibleCondition limits articles to the ones the viewer may open by
+// slug: their own, and published ones unless they are for followers only and
+// the viewer doesn't follow the author. Trashed articles are never visible.
+// Takes the viewer id twice.
+const articleVisibleCondition = `a."deleted_at" IS NULL AND (a."id_author" = ? OR (a."status" = 'published' AND (a."visibility" <> 'followers' OR EXISTS (SELECT 1 FROM "user_follow" AS vf WHERE vf."id_user_a" = ? AND vf."id_user_b" = a."id_author"))))`
+
+// articleFromQueryResult turns a row of the article queries into the
+// article response.
+func articleFromQueryResult(r models.ArticleQueryResult) models.ArticleCommon {
+	tagList := []string(r.TagList)
+	if tagList == nil {
+		tagList = make([]string, 0)
+	}
+
+	return models.ArticleCommon{
+		ID:             r.ID,
+		Slug:           r.Slug,
+		Title:          r.Title,
+		Description:    r.Description,
+		Body:           r.Body,
+		TagList:        tagList,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		Status:         r.Status,
+		PublishAt:      r.PublishAt,
+		Visibility:     r.Visibility,
+		Favorited:      r.Favorited,
+		FavoritesCount: r.FavoritesCount,
+		CommentsCount:  r.CommentsCount,
+		Author: &models.UserProfile{
+			Username:  r.Username,
+			Bio:       r.Bio,
+			Image:     r.Image,
+			Following: r.Following,
+		},
+	}
 }
 
-func NewArticleController(DB *gorm.DB) ArticleController {
-	return ArticleController{DB}
+// orderArticlesByID puts articles in the order of ids.
+func orderArticlesByID(articles []models.ArticleCommon, ids []int32) []models.ArticleCommon {
+	byID := make(map[int32]models.ArticleCommon, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	ordered := make([]models.ArticleCommon, 0, len(articles))
+	for _, id := range ids {
+		if article, ok := byID[id]; ok {
+			ordered = append(ordered, article)
+		}
+	}
+	return ordered
 }
 
-func (ac *ArticleController) CreateArticle(ctx *gin.Context) {
-	currentUser := ctx.MustGet("currentUser").(models.User)
+// resolveVisibility validates the requested visibility, falling back to
+// current when none is given.
+func resolveVisibility(current string, visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return current, nil
+	case models.ArticleVisibilityPublic, models.ArticleVisibilityUnlisted, models.ArticleVisibilityFollowers:
+		return visibility, nil
+	default:
+		return "", fmt.Errorf("visibility must be one of %s, %s or %s", models.ArticleVisibilityPublic, models.ArticleVisibilityUnlisted, models.ArticleVisibilityFollowers)
+	}
+}
 
-	var payload *models.ArticleCreateRequest
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
-		return
+// resolvePublication works out the status and publish time an article ends up
+// with when a client asks for status and publishAt. current is empty for new
+// articles.
+func resolvePublication(current string, currentPublishAt *time.Time, status string, publishAt *time.Time, now time.Time) (string, *time.Time, error) {
+	if len(status) == 0 {
+		switch {
+		case len(current) != 0 && publishAt == nil:
+			return current, currentPublishAt, nil
+		case publishAt != nil && publishAt.After(now):
+			status = models.ArticleStatusScheduled
+		default:
+			status = models.ArticleStatusPublished
+		}
 	}
 
-	processedSlug := utils.GenerateSlug(payload.Article.Title)
+	switch status {
+	case models.ArticleStatusDraft:
+		if publishAt != nil {
+			return status, publishAt, nil
+		}
+		return status, currentPublishAt, nil
+	case models.ArticleStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return "", nil, errors.New("publishAt must be in the future to schedule an article")
+		}
+		return status, publishAt, nil
+	case models.ArticleStatusPublished:
+		if current == models.ArticleStatusPublished {
+			return status, currentPublishAt, nil
+		}
+		return status, &now, nil
+	case models.ArticleStatusUnpublished:
+		if current != models.ArticleStatusPublished && current != models.ArticleStatusUnpublished {
+			return "", nil, errors.New("only published articles can be unpublished")
+		}
+		return status, currentPublishAt, nil
+	default:
+		return "", nil, fmt.Errorf("status must be one of %s, %s, %s or %s", models.ArticleStatusDraft, models.ArticleStatusScheduled, models.ArticleStatusPublished, models.ArticleStatusUnpublished)
+	}
+}
 
-	queryInsert := `INSERT INTO articles (id_author, slug, title, description, body)
-						VALUES (?, ?, ?, ?, ?) RETURNING *`
+type ArticleController struct {
+	DB       *gorm.DB
+	Renderer *markdown.Renderer
+}
 
-	var article models.Article
-	processInsert := ac.DB.Raw(queryInsert, currentUser.ID, processedSlug, payload.Article.Title, payload.Article.Description, payload.Article.Body).Scan(&article)
-	if processInsert.Error != nil || article.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processInsert.Error.Error()})
-		return
+func NewArticleController(DB *gorm.DB, Renderer *markdown.Renderer) ArticleController {
+	return ArticleController{DB, Renderer}
+}
+
+// withRendering adds the rendered body, table of contents, word count and
+// reading time to the article when the client asks for ?render=html.
+func (ac *ArticleController) withRendering(ctx *gin.Context, id int32, article *models.ArticleCommon) error {
+	if ctx.Query("render") != "html" {
+		return nil
 	}
 
-	for _, t := range payload.Article.TagList {
-		loweredTag := strings.ToLower(strings.TrimSpace(t))
+	rendered, err := ac.Renderer.Render(fmt.Sprintf("%d:%d", id, article.UpdatedAt.UnixNano()), article.Body)
+	if err != nil {
+		return err
+	}
 
-		var tag models.Tag
-		queryResultTag := ac.DB.Raw(`SELECT * FROM tags WHERE name = ?`, loweredTag).Scan(&tag)
+	article.BodyHTML = &rendered.HTML
+	article.TOC = rendered.TOC
+	article.WordCount = &rendered.WordCount
+	article.ReadingTime = &rendered.ReadingTime
+	return nil
+}
 
-		// if tag not found then insert into table tags
-		if queryResultTag.Error != nil || tag.ID == 0 {
-			processInsertTag := ac.DB.Raw(`INSERT INTO tags ("name") VALUES (?) RETURNING *`, loweredTag).Scan(&tag)
-			if processInsertTag.Error != nil || article.ID == 0 {
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processInsertTag.Error.Error()})
-				return
-			}
-		}
+func (ac *ArticleController) findArticleByID(ctx *gin.Context, viewerID int32, articleID int32) (*models.ArticleCommon, error) {
+	return ac.findArticle(ctx, viewerID, `a."id" = ?`, articleID)
+}
 
-		processInsertArticleTag := ac.DB.Exec(`INSERT INTO article_tag VALUES (?, ?)`, article.ID, tag.ID)
-		if processInsertArticleTag.Error != nil {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processInsertArticleTag.Error.Error()})
-			return
-		}
+// findArticle builds the response for the single article matching condition
+// as seen by the viewer. A nil article without error means it doesn't exist.
+func (ac *ArticleController) findArticle(ctx *gin.Context, viewerID int32, condition string, args ...interface{}) (*models.ArticleCommon, error) {
+	articles, err := ac.findArticles(ctx, viewerID, condition, "", args...)
+	if err != nil || len(articles) == 0 {
+		return nil, err
 	}
 
-	//find article
-	queryFind := `
-        SELECT  
-          --  a."id", 
+	return &articles[0], nil
+}
+
+// articleColumns are the columns of an article response, read from articles
+// a joined with their author u. The viewer's id is bound twice, for the
+// following and favorited flags.
+const articleColumns = `
+          a."id",
 		  a."slug", 
 		  a."title", 
 		  a."description", 
 		  a."body", 
 		  a."created_at", 
 		  a."updated_at", 
-		--  a."id_author", 
+		  a."id_author", 
+		  a."status", 
+		  a."publish_at", 
+		  a."visibility", 
 		  u."username", 
 		  u."bio", 
 		  u."image", 
-		  CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following", 
-		--  att."id_tag" AS "tag_id", 
-		  t."name" AS "tag_name", 
-		--  l."id_user" AS "liked_by", 
-		--  ul."username" AS "liked_by_username", 
-		  CASE WHEN l."id_user" IS NULL THEN FALSE ELSE TRUE END AS "favorited",
-		  z."favorites_count"
+		  EXISTS (SELECT 1 FROM "user_follow" AS f WHERE f."id_user_a" = ? AND f."id_user_b" = u."id") AS "following", 
+		  EXISTS (SELECT 1 FROM "user_likes" AS vl WHERE vl."id_article" = a."id" AND vl."id_user" = ?) AS "favorited",
+		  a."favorites_count",
+		  a."comments_count",
+		  COALESCE((
+		    SELECT json_agg(t."name" ORDER BY t."name")
+		    FROM "article_tag" AS att
+		    INNER JOIN "tags" AS t ON t."id" = att."id_tag"
+		    WHERE att."id_article" = a."id"
+		  ), '[]') AS "tag_list"`
+
+// findArticles builds the responses for the articles matching condition as
+// seen by the viewer, sorted by order or else newest id first. Tags and the
+// viewer's flags are aggregated per article, so there's one row each.
+func (ac *ArticleController) findArticles(ctx *gin.Context, viewerID int32, condition string, order string, args ...interface{}) ([]models.ArticleCommon, error) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	if len(order) == 0 {
+		order = `a."id" DESC`
+	}
+
+	query := `
+        SELECT ` + articleColumns + `
         FROM "articles" AS a 
         INNER JOIN "users" AS u ON u."id" = a."id_author" 
-        LEFT JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id"
-        LEFT JOIN "article_tag" AS att ON att."id_article" = a."id" 
-        LEFT JOIN "tags" AS t ON t."id" = att."id_tag" 
-        LEFT JOIN "user_likes" AS l ON l."id_article" = a."id" 
-        LEFT JOIN "users" AS ul ON ul."id" = l."id_user"
-        LEFT JOIN (SELECT y."id_article", COUNT(y."id_article") AS "favorites_count" FROM "user_likes" AS y WHERE y."id_article" = ? GROUP BY y."id_article") AS z ON z."id_article" = a."id"
-        WHERE a."id" = ? 
-        ORDER BY a."created_at" DESC`
+        WHERE ` + condition + `
+        ORDER BY ` + order
 
 	var resultModel []models.ArticleQueryResult
-	resultQueryArticle := ac.DB.Raw(queryFind, currentUser.ID, article.ID, article.ID).Scan(&resultModel)
-	if resultQueryArticle.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": resultQueryArticle.Error.Error()})
-		return
+	processQuery := db.Raw(query, append([]interface{}{viewerID, viewerID}, args...)...).Scan(&resultModel)
+	if processQuery.Error != nil {
+		return nil, processQuery.Error
 	}
 
-	tagList := make(map[string]bool)
-
+	articles := make([]models.ArticleCommon, 0, len(resultModel))
 	for _, r := range resultModel {
-		tagList[r.TagName] = true
-	}
-
-	// Convert map to slice of keys.
-	var tagKeys []string
-	for key, _ := range tagList {
-		tagKeys = append(tagKeys, key)
-	}
-
-	uniqueArticleRes := resultModel[0]
-
-	articleResponse := &models.ArticleResponse{
-		Article: &models.ArticleCommon{
-			Slug:           uniqueArticleRes.Slug,
-			Title:          uniqueArticleRes.Title,
-			Description:    uniqueArticleRes.Description,
-			Body:           uniqueArticleRes.Body,
-			TagList:        tagKeys,
-			CreatedAt:      uniqueArticleRes.CreatedAt,
-			UpdatedAt:      uniqueArticleRes.UpdatedAt,
-			Favorited:      uniqueArticleRes.Favorited,
-			FavoritesCount: uniqueArticleRes.FavoritesCount,
-			Author: &models.UserProfile{
-				Username:  uniqueArticleRes.Username,
-				Bio:       uniqueArticleRes.Bio,
-				Image:     uniqueArticleRes.Image,
-				Following: uniqueArticleRes.Following,
-			},
-		},
+		articles = append(articles, articleFromQueryResult(r))
 	}
-
-	ctx.JSON(http.StatusCreated, articleResponse)
-
+	return articles, nil
 }
 
-func (ac *ArticleController) GetAllArticles(ctx *gin.Context) {
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
-	tag := ctx.DefaultQuery("tag", "")
-	author := ctx.DefaultQuery("author", "")
-	favorited := ctx.DefaultQuery("favorited", "")
-
-	if len(tag) == 0 && len(author) == 0 && len(favorited) == 0 {
-		tag = "%" + tag + "%"
-		author = "%" + author + "%"
-		favorited = "%" + favorited + "%"
-	} else {
-		if len(tag) != 0 {
-			tag = "%" + tag + "%"
-		}
+func (ac *ArticleController) CreateArticle(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
 
-		if len(author) != 0 {
-			author = "%" + author + "%"
-		}
+	currentUser := ctx.MustGet("currentUser").(models.User)
 
-		if len(favorited) != 0 {
-			favorited = "%" + favorited + "%"
-		}
+	var payload *models.ArticleCreateRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
+		return
 	}
 
-	offset := (limit * page) - limit
-
-	query := `
-        SELECT
-          a."id",
-		  a."slug", 
-		  a."title", 
-		  a."description", 
-		  a."body", 
-		  a."created_at", 
-		  a."updated_at", 
-		  u."username", 
-		  u."bio", 
-		  u."image", 
-		  CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following", 
-		  t."name" AS "tag_name", 
-		  CASE WHEN l."id_user" IS NULL THEN FALSE ELSE FALSE END AS "favorited",
-		  z."favorites_count"
-        FROM "articles" AS a 
-        INNER JOIN "users" AS u ON u."id" = a."id_author" 
-        LEFT JOIN "user_follow" AS f ON f."id_user_b" = u."id"
-		LEFT JOIN "article_tag" AS att ON att."id_article" = a."id" 
-        LEFT JOIN "tags" AS t ON t."id" = att."id_tag" 
-        LEFT JOIN "user_likes" AS l ON l."id_article" = a."id"
-        LEFT JOIN "users" AS ul ON ul."id" = l."id_user"
-        LEFT JOIN (SELECT y."id_article", COUNT(y."id_article") AS "favorites_count" FROM "user_likes" AS y GROUP BY y."id_article") AS z ON z."id_article" = a."id"
-        WHERE u."username" ILIKE ? OR t."name" ILIKE ? OR ul."username" ILIKE ?
-        ORDER BY a."created_at" DESC
-        LIMIT ? 
-        OFFSET ?`
-
-	var resultModel []models.ArticleQueryResult
-	processQuery := ac.DB.Raw(query, author, tag, favorited, limit, offset).Scan(&resultModel)
-	if processQuery.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processQuery.Error.Error()})
+	status, publishAt, err := resolvePublication("", nil, payload.Article.Status, payload.Article.PublishAt, time.Now())
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Validation(err.Error()))
 		return
 	}
 
-	var articleResponseArray []models.ArticleCommon
+	visibility, err := resolveVisibility(models.ArticleVisibilityPublic, payload.Article.Visibility)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Validation(err.Error()))
+		return
+	}
 
-	uniqueArticles := make(map[int32]models.ArticleQueryResult)
+	processedSlug := utils.GenerateSlug(payload.Article.Title)
 
-	for _, r := range resultModel {
-		uniqueArticles[r.ID] = r
-	}
+	queryInsert := `INSERT INTO articles (id_author, slug, title, description, body, status, publish_at, visibility)
+						VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING *`
 
-	var articles []models.ArticleQueryResult
-	for _, value := range uniqueArticles {
-		articles = append(articles, value)
+	// tags are stored lowered, so "Go" and "go" are the same tag
+	tagNames := make([]string, 0, len(payload.Article.TagList))
+	seenTags := make(map[string]bool, len(payload.Article.TagList))
+	for _, t := range payload.Article.TagList {
+		loweredTag := strings.ToLower(strings.TrimSpace(t))
+		if len(loweredTag) == 0 || seenTags[loweredTag] {
+			continue
+		}
+		seenTags[loweredTag] = true
+		tagNames = append(tagNames, loweredTag)
 	}
 
-	for _, article := range articles {
-		tagList := make(map[string]bool)
+	// the upsert hands back the id of a tag created concurrently as well
+	queryUpsertTag := `INSERT INTO tags ("name") VALUES (?) ON CONFLICT ("name") DO UPDATE SET "name" = EXCLUDED."name" RETURNING id`
 
-		for _, r := range resultModel {
-			if article.ID == r.ID {
-				tagList[r.TagName] = true
-			}
+	var article models.Article
+	err = db.Transaction(func(tx *gorm.DB) error {
+		processInsert := tx.Raw(queryInsert, currentUser.ID, processedSlug, payload.Article.Title, payload.Article.Description, payload.Article.Body, status, publishAt, visibility).Scan(&article)
+		if processInsert.Error != nil {
+			return processInsert.Error
 		}
 
-		// Convert map to slice of keys.
-		var tagKeys []string
-		for key, _ := range tagList {
-			tagKeys = append(tagKeys, key)
+		processRevision := tx.Exec(queryInsertRevision, article.ID, currentUser.ID, article.Title, article.Description, article.Body, article.CreatedAt, article.ID)
+		if processRevision.Error != nil {
+			return processRevision.Error
 		}
 
-		articleObj := &models.ArticleCommon{
-			Slug:           article.Slug,
-			Title:          article.Title,
-			Description:    article.Description,
-			Body:           article.Body,
-			TagList:        tagKeys,
-			CreatedAt:      article.CreatedAt,
-			UpdatedAt:      article.UpdatedAt,
-			Favorited:      article.Favorited,
-			FavoritesCount: article.FavoritesCount,
-			Author: &models.UserProfile{
-				Username:  article.Username,
-				Bio:       article.Bio,
-				Image:     article.Image,
-				Following: article.Following,
-			},
+		for _, name := range tagNames {
+			var tagID int32
+			if err := tx.Raw(queryUpsertTag, name).Scan(&tagID).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`INSERT INTO article_tag VALUES (?, ?)`, article.ID, tagID).Error; err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		apperrors.Abort(ctx, dbError(err))
+		return
+	}
 
-		articleResponseArray = append(articleResponseArray, *articleObj)
+	articleResponse, err := ac.findArticleByID(ctx, currentUser.ID, article.ID)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	} else if articleResponse == nil {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"articles": articleResponseArray, "articlesCount": len(articleResponseArray)})
+	if err := ac.withRendering(ctx, article.ID, articleResponse); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.ArticleResponse{Article: articleResponse})
+
 }
 
-func (ac *ArticleController) GetFeedArticles(ctx *gin.Context) {
-	currentUser := ctx.MustGet("currentUser").(models.User)
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+func (ac *ArticleController) GetAllArticles(ctx *gin.Context) {
+	filter, problems := parseArticleFilter(ctx)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
 
-	offset := (limit * page) - limit
+	condition, args := filter.condition()
+	ks := articleKeyset(filter.Sort, filter.Cursor, filter.Limit)
 
-	query := `
-        SELECT
-          a."id",
-		  a."slug", 
-		  a."title", 
-		  a."description", 
-		  a."body", 
-		  a."created_at", 
-		  a."updated_at", 
-		  u."username", 
-		  u."bio", 
-		  u."image", 
-		  CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following", 
-		  t."name" AS "tag_name", 
-		  CASE WHEN l."id_user" IS NULL THEN FALSE ELSE TRUE END AS "favorited",
-		  z."favorites_count"
-        FROM "articles" AS a 
-        INNER JOIN "users" AS u ON u."id" = a."id_author" 
-        INNER JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id"
-		LEFT JOIN "article_tag" AS att ON att."id_article" = a."id" 
-        LEFT JOIN "tags" AS t ON t."id" = att."id_tag" 
-        LEFT JOIN "user_likes" AS l ON l."id_article" = a."id"
-        LEFT JOIN "users" AS ul ON ul."id" = l."id_user"
-        LEFT JOIN (SELECT y."id_article", COUNT(y."id_article") AS "favorites_count" FROM "user_likes" AS y GROUP BY y."id_article") AS z ON z."id_article" = a."id"
-        ORDER BY a."created_at" DESC
-        LIMIT ? 
-        OFFSET ?`
+	ac.listArticles(ctx, 0, condition, args, ks, filter.Offset, filter.EstimateCount)
+}
 
-	var resultModel []models.ArticleQueryResult
-	processQuery := ac.DB.Raw(query, currentUser.ID, limit, offset).Scan(&resultModel)
-	if processQuery.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processQuery.Error.Error()})
+func (ac *ArticleController) GetFeedArticles(ctx *gin.Context) {
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	limit, offset, cursor, problems := parsePage(ctx, 10)
+	sort, sortProblems := parseArticleSort(ctx, cursor)
+	problems = append(problems, sortProblems...)
+	estimate, countProblems := parseCountMode(ctx)
+	problems = append(problems, countProblems...)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
 		return
 	}
 
-	var articleResponseArray []models.ArticleCommon
+	condition := `a."deleted_at" IS NULL AND a."status" = 'published' AND a."visibility" IN ('public', 'followers')
+        AND EXISTS (SELECT 1 FROM "user_follow" AS f WHERE f."id_user_a" = ? AND f."id_user_b" = a."id_author")`
+	ks := articleKeyset(sort, cursor, limit)
 
-	articleResponseArray = make([]models.ArticleCommon, 0)
+	ac.listArticles(ctx, currentUser.ID, condition, []interface{}{currentUser.ID}, ks, offset, estimate)
+}
 
-	uniqueArticles := make(map[int32]models.ArticleQueryResult)
+// listArticles responds with a page of the articles matching condition
+// together with their total and the pagination metadata. An exact total is
+// counted by the same statement as the page, an estimate needs EXPLAIN and
+// so a statement of its own.
+func (ac *ArticleController) listArticles(ctx *gin.Context, viewerID int32, condition string, args []interface{}, ks *keyset, offset int, estimate bool) {
+	db := ac.DB.WithContext(ctx.Request.Context())
 
-	for _, r := range resultModel {
-		uniqueArticles[r.ID] = r
+	articleResponseArray, nextCursor, prevCursor, total, err := ac.pageArticles(ctx, viewerID, condition, args, ks, offset, !estimate)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
 	}
 
-	var articles []models.ArticleQueryResult
-	for _, value := range uniqueArticles {
-		articles = append(articles, value)
+	if estimate {
+		queryMatching := `SELECT a."id" FROM "articles" AS a INNER JOIN "users" AS u ON u."id" = a."id_author" WHERE ` + condition
+		total, err = countRows(db, queryMatching, args, true)
+		if err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
 	}
 
-	for _, article := range articles {
-		tagList := make(map[string]bool)
-
-		for _, r := range resultModel {
-			if article.ID == r.ID {
-				tagList[r.TagName] = true
-			}
-		}
+	response := pageMetadata(ctx, pagination{
+		Limit:  ks.Limit,
+		Offset: offset,
+		Cursor: ks.Cursor,
+		Count:  len(articleResponseArray),
+		Total:  &total,
+		Next:   nextCursor,
+		Prev:   prevCursor,
+	})
+	response["articles"] = articleResponseArray
+	response["articlesCount"] = total
+	response["articlesCountEstimated"] = estimate
+
+	ctx.JSON(http.StatusOK, response)
+}
 
-		// Convert map to slice of keys.
-		var tagKeys []string
-		for key, _ := range tagList {
-			tagKeys = append(tagKeys, key)
+// pageArticles loads one page of the articles matching condition, read
+// through ks, as seen by the viewer, in a single statement. The page of ids
+// is picked first so the tag aggregation only runs for the rows returned,
+// and the page is left joined to the count so a page past the end still
+// reads the total, as a row without an article. The total is only counted
+// when count is set.
+func (ac *ArticleController) pageArticles(ctx *gin.Context, viewerID int32, condition string, args []interface{}, ks *keyset, offset int, count bool) ([]models.ArticleCommon, *string, *string, int64, error) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	keyCondition, keyArgs := ks.condition()
+
+	sortKeyColumn := `"sort_time"`
+	if articleSorts[ks.Sort].Numeric {
+		sortKeyColumn = `"sort_number"`
+	}
+
+	// the page comes out in the order it was read in
+	pageOrder := *ks
+	pageOrder.Key, pageOrder.ID = `p."sort_key"`, `p."id"`
+
+	queryTotal := `SELECT NULL::bigint AS "total"`
+	var totalArgs []interface{}
+	if count {
+		queryTotal = `
+            SELECT COUNT(*) AS "total"
+            FROM "articles" AS a
+            INNER JOIN "users" AS u ON u."id" = a."id_author"
+            WHERE ` + condition
+		totalArgs = args
+	}
+
+	queryPage := `
+        WITH "page" AS (
+            SELECT a."id", ` + ks.Key + ` AS "sort_key"
+            FROM "articles" AS a
+            INNER JOIN "users" AS u ON u."id" = a."id_author"
+            WHERE ` + condition + ` AND ` + keyCondition + `
+            ORDER BY ` + ks.order() + `
+            LIMIT ?
+            OFFSET ?
+        )
+        SELECT ` + articleColumns + `,
+          p."sort_key" AS ` + sortKeyColumn + `,
+          c."total"
+        FROM (` + queryTotal + `) AS c
+        LEFT JOIN "page" AS p ON TRUE
+        LEFT JOIN "articles" AS a ON a."id" = p."id"
+        LEFT JOIN "users" AS u ON u."id" = a."id_author"
+        ORDER BY ` + pageOrder.order()
+
+	if ks.Cursor != nil {
+		offset = 0
+	}
+
+	queryArgs := append(append(append([]interface{}{}, args...), keyArgs...), ks.Limit+1, offset, viewerID, viewerID)
+	queryArgs = append(queryArgs, totalArgs...)
+
+	var rows []models.ArticlePageRow
+	processPage := db.Raw(queryPage, queryArgs...).Scan(&rows)
+	if processPage.Error != nil {
+		return nil, nil, nil, 0, processPage.Error
+	}
+
+	var total int64
+	if len(rows) != 0 && rows[0].Total != nil {
+		total = *rows[0].Total
+	}
+	if len(rows) == 1 && rows[0].ID == 0 {
+		rows = rows[:0]
+	}
+
+	rows, nextCursor, prevCursor := pageKeyset(ks, rows, offset > 0, func(r models.ArticlePageRow) (interface{}, int32) {
+		if r.SortNumber != nil {
+			return *r.SortNumber, r.ID
 		}
-
-		articleObj := &models.ArticleCommon{
-			Slug:           article.Slug,
-			Title:          article.Title,
-			Description:    article.Description,
-			Body:           article.Body,
-			TagList:        tagKeys,
-			CreatedAt:      article.CreatedAt,
-			UpdatedAt:      article.UpdatedAt,
-			Favorited:      article.Favorited,
-			FavoritesCount: article.FavoritesCount,
-			Author: &models.UserProfile{
-				Username:  article.Username,
-				Bio:       article.Bio,
-				Image:     article.Image,
-				Following: article.Following,
-			},
+		return r.SortTime, r.ID
+	})
+
+	articles := make([]models.ArticleCommon, 0, len(rows))
+	for _, r := range rows {
+		article := articleFromQueryResult(r.ArticleQueryResult)
+		if err := ac.withRendering(ctx, article.ID, &article); err != nil {
+			return nil, nil, nil, 0, err
 		}
-
-		articleResponseArray = append(articleResponseArray, *articleObj)
+		articles = append(articles, article)
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"articles": articleResponseArray, "articlesCount": len(articleResponseArray)})
+	return articles, nextCursor, prevCursor, total, nil
 }
 
 func (ac *ArticleController) GetArticleBySlug(ctx *gin.Context) {
 	currentUser := ctx.MustGet("currentUser").(models.User)
 	slug := ctx.Param("slug")
 
-	query := `
-        SELECT
-          a."id",
-		  a."slug", 
-		  a."title", 
-		  a."description", 
-		  a."body", 
-		  a."created_at", 
-		  a."updated_at", 
-		  u."username", 
-		  u."bio", 
-		  u."image", 
-		  CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following", 
-		  t."name" AS "tag_name", 
-		  CASE WHEN l."id_user" IS NULL THEN FALSE ELSE TRUE END AS "favorited",
-		  z."favorites_count"
-        FROM "articles" AS a 
-        INNER JOIN "users" AS u ON u."id" = a."id_author" 
-        LEFT JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id"
-		LEFT JOIN "article_tag" AS att ON att."id_article" = a."id" 
-        LEFT JOIN "tags" AS t ON t."id" = att."id_tag" 
-        LEFT JOIN "user_likes" AS l ON l."id_article" = a."id"
-        LEFT JOIN "users" AS ul ON ul."id" = l."id_user"
-        LEFT JOIN (SELECT y."id_article", COUNT(y."id_article") AS "favorites_count" FROM "user_likes" AS y GROUP BY y."id_article") AS z ON z."id_article" = a."id"
-        WHERE a."slug" = ?`
-
-	var resultModel []models.ArticleQueryResult
-	processQuery := ac.DB.Raw(query, currentUser.ID, slug).Scan(&resultModel)
-	if processQuery.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processQuery.Error.Error()})
+	articleResponse, err := ac.findArticle(ctx, currentUser.ID, `a."slug" = ? AND `+articleVisibleCondition, slug, currentUser.ID, currentUser.ID)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
-	} else if len(resultModel) == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+	} else if articleResponse == nil {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
-	article := resultModel[0]
+	if err := ac.withRendering(ctx, articleResponse.ID, articleResponse); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
 
-	tagList := make(map[string]bool)
+	ctx.JSON(http.StatusOK, gin.H{"article": articleResponse})
+}
 
-	for _, r := range resultModel {
-		if article.ID == r.ID {
-			tagList[r.TagName] = true
-		}
-	}
+func (ac *ArticleController) GetDraftArticles(ctx *gin.Context) {
+	currentUser := ctx.MustGet("currentUser").(models.User)
 
-	// Convert map to slice of keys.
-	var tagKeys []string
-	for key, _ := range tagList {
-		tagKeys = append(tagKeys, key)
+	limit, offset, cursor, problems := parsePage(ctx, 10)
+	problems = append(problems, checkCursor(cursor, "updated", false)...)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
 	}
 
-	articleResponse := &models.ArticleCommon{
-		Slug:           article.Slug,
-		Title:          article.Title,
-		Description:    article.Description,
-		Body:           article.Body,
-		TagList:        tagKeys,
-		CreatedAt:      article.CreatedAt,
-		UpdatedAt:      article.UpdatedAt,
-		Favorited:      article.Favorited,
-		FavoritesCount: article.FavoritesCount,
-		Author: &models.UserProfile{
-			Username:  article.Username,
-			Bio:       article.Bio,
-			Image:     article.Image,
-			Following: article.Following,
-		},
-	}
+	// drafts and scheduled articles, most recently edited first
+	condition := `a."id_author" = ? AND a."deleted_at" IS NULL AND a."status" <> 'published'`
+	ks := articleKeyset("updated", cursor, limit)
 
-	ctx.JSON(http.StatusOK, gin.H{"article": articleResponse})
+	ac.listArticles(ctx, currentUser.ID, condition, []interface{}{currentUser.ID}, ks, offset, false)
 }
 
 func (ac *ArticleController) UpdateArticle(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
 	currentUser := ctx.MustGet("currentUser").(models.User)
 	slug := ctx.Param("slug")
 
 	var payload *models.ArticleUpdateRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
 		return
 	}
 
-	querySingleArticle := `SELECT a."id", a."title", a."slug", a."description", a."body" FROM "articles" AS a WHERE a."slug" = ?`
+	querySingleArticle := `SELECT a."id", a."id_author", a."title", a."slug", a."description", a."body", a."status", a."publish_at", a."visibility" FROM "articles" AS a WHERE a."slug" = ? AND ` + articleVisibleCondition
 	var oldArticle models.Article
-	processGetArticle := ac.DB.Raw(querySingleArticle, slug).Scan(&oldArticle)
+	processGetArticle := db.Raw(querySingleArticle, slug, currentUser.ID, currentUser.ID).Scan(&oldArticle)
 	if processGetArticle.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": processGetArticle.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(processGetArticle.Error))
 		return
 	} else if oldArticle.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return
+	} else if oldArticle.IDAuthor != currentUser.ID {
+		apperrors.Abort(ctx, apperrors.Forbidden("only the author can update an article"))
 		return
 	}
 
@@ -466,183 +564,99 @@ func (ac *ArticleController) UpdateArticle(ctx *gin.Context) {
 		bodyUpdate = payload.Article.Body
 	}
 
-	queryUpdateArticle := `UPDATE articles SET title = ?, slug = ?, description = ?, body = ?, updated_at = ? WHERE id = ? RETURNING id`
 	now := time.Now()
-	var articleUpdated models.Article
-	processUpdate := ac.DB.Raw(queryUpdateArticle, titleUpdate, slugUpdate, descriptionUpdate, bodyUpdate, now, oldArticle.ID).Scan(&articleUpdated)
-	if processUpdate.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": processUpdate.Error.Error()})
-		return
-	} else if articleUpdated.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": "Update Failed"})
+	statusUpdate, publishAtUpdate, err := resolvePublication(oldArticle.Status, oldArticle.PublishAt, payload.Article.Status, payload.Article.PublishAt, now)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Validation(err.Error()))
 		return
 	}
 
-	// construct Article Response
-	query := `
-        SELECT
-          a."id",
-		  a."slug", 
-		  a."title", 
-		  a."description", 
-		  a."body", 
-		  a."created_at", 
-		  a."updated_at", 
-		  u."username", 
-		  u."bio", 
-		  u."image", 
-		  CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following", 
-		  t."name" AS "tag_name", 
-		  CASE WHEN l."id_user" IS NULL THEN FALSE ELSE TRUE END AS "favorited",
-		  z."favorites_count"
-        FROM "articles" AS a 
-        INNER JOIN "users" AS u ON u."id" = a."id_author" 
-        LEFT JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id"
-		LEFT JOIN "article_tag" AS att ON att."id_article" = a."id" 
-        LEFT JOIN "tags" AS t ON t."id" = att."id_tag" 
-        LEFT JOIN "user_likes" AS l ON l."id_article" = a."id"
-        LEFT JOIN "users" AS ul ON ul."id" = l."id_user"
-        LEFT JOIN (SELECT y."id_article", COUNT(y."id_article") AS "favorites_count" FROM "user_likes" AS y GROUP BY y."id_article") AS z ON z."id_article" = a."id"
-        WHERE a."id" = ?`
-
-	var resultModel []models.ArticleQueryResult
-	processQuery := ac.DB.Raw(query, currentUser.ID, articleUpdated.ID).Scan(&resultModel)
-	if processQuery.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processQuery.Error.Error()})
-		return
-	} else if len(resultModel) == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+	visibilityUpdate, err := resolveVisibility(oldArticle.Visibility, payload.Article.Visibility)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Validation(err.Error()))
 		return
 	}
 
-	article := resultModel[0]
-
-	tagList := make(map[string]bool)
-
-	for _, r := range resultModel {
-		if article.ID == r.ID {
-			tagList[r.TagName] = true
+	// scoped to the author as well, visibility and status are theirs alone to change
+	queryUpdateArticle := `UPDATE articles SET title = ?, slug = ?, description = ?, body = ?, status = ?, publish_at = ?, visibility = ?, updated_at = ? WHERE id = ? AND id_author = ? RETURNING id`
+	var articleUpdated models.Article
+	err = db.Transaction(func(tx *gorm.DB) error {
+		processUpdate := tx.Raw(queryUpdateArticle, titleUpdate, slugUpdate, descriptionUpdate, bodyUpdate, statusUpdate, publishAtUpdate, visibilityUpdate, now, oldArticle.ID, currentUser.ID).Scan(&articleUpdated)
+		if processUpdate.Error != nil {
+			return processUpdate.Error
+		} else if articleUpdated.ID == 0 {
+			return errors.New("Update Failed")
 		}
+
+		// the UPDATE above holds the row lock, so revision numbers can't race
+		return tx.Exec(queryInsertRevision, oldArticle.ID, currentUser.ID, titleUpdate, descriptionUpdate, bodyUpdate, now, oldArticle.ID).Error
+	})
+	if err != nil {
+		apperrors.Abort(ctx, dbError(err))
+		return
 	}
 
-	// Convert map to slice of keys.
-	var tagKeys []string
-	for key, _ := range tagList {
-		tagKeys = append(tagKeys, key)
+	articleResponse, err := ac.findArticleByID(ctx, currentUser.ID, articleUpdated.ID)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	} else if articleResponse == nil {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return
 	}
 
-	articleResponse := &models.ArticleCommon{
-		Slug:           article.Slug,
-		Title:          article.Title,
-		Description:    article.Description,
-		Body:           article.Body,
-		TagList:        tagKeys,
-		CreatedAt:      article.CreatedAt,
-		UpdatedAt:      article.UpdatedAt,
-		Favorited:      article.Favorited,
-		FavoritesCount: article.FavoritesCount,
-		Author: &models.UserProfile{
-			Username:  article.Username,
-			Bio:       article.Bio,
-			Image:     article.Image,
-			Following: article.Following,
-		},
+	if err := ac.withRendering(ctx, articleUpdated.ID, articleResponse); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"article": articleResponse})
 }
 
 func (ac *ArticleController) FavoriteArticle(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
 	currentUser := ctx.MustGet("currentUser").(models.User)
 	slug := ctx.Param("slug")
 
-	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ?`
+	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ? AND ` + articleVisibleCondition
 	var oldArticle models.Article
-	processGetArticle := ac.DB.Raw(querySingleArticle, slug).Scan(&oldArticle)
+	processGetArticle := db.Raw(querySingleArticle, slug, currentUser.ID, currentUser.ID).Scan(&oldArticle)
 	if processGetArticle.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": processGetArticle.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(processGetArticle.Error))
 		return
 	} else if oldArticle.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
-	query := `INSERT INTO user_likes (id_user, id_article) VALUES (?, ?)`
-	process := ac.DB.Exec(query, currentUser.ID, oldArticle.ID)
-	if process.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": process.Error.Error()})
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// favoriting twice is a no-op, only a new favorite is counted
+		query := `INSERT INTO user_likes (id_user, id_article) VALUES (?, ?) ON CONFLICT DO NOTHING`
+		process := tx.Exec(query, currentUser.ID, oldArticle.ID)
+		if process.Error != nil || process.RowsAffected == 0 {
+			return process.Error
+		}
+
+		return tx.Exec(`UPDATE articles SET favorites_count = favorites_count + 1 WHERE id = ?`, oldArticle.ID).Error
+	})
+	if err != nil {
+		apperrors.Abort(ctx, dbError(err))
 		return
 	}
 
-	// construct Article Response
-	queryArticle := `
-        SELECT
-          a."id",
-		  a."slug", 
-		  a."title", 
-		  a."description", 
-		  a."body", 
-		  a."created_at", 
-		  a."updated_at", 
-		  u."username", 
-		  u."bio", 
-		  u."image", 
-		  CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following", 
-		  t."name" AS "tag_name", 
-		  CASE WHEN l."id_user" IS NULL THEN FALSE ELSE TRUE END AS "favorited",
-		  z."favorites_count"
-        FROM "articles" AS a 
-        INNER JOIN "users" AS u ON u."id" = a."id_author" 
-        LEFT JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id"
-		LEFT JOIN "article_tag" AS att ON att."id_article" = a."id" 
-        LEFT JOIN "tags" AS t ON t."id" = att."id_tag" 
-        LEFT JOIN "user_likes" AS l ON l."id_article" = a."id"
-        LEFT JOIN "users" AS ul ON ul."id" = l."id_user"
-        LEFT JOIN (SELECT y."id_article", COUNT(y."id_article") AS "favorites_count" FROM "user_likes" AS y GROUP BY y."id_article") AS z ON z."id_article" = a."id"
-        WHERE a."id" = ?`
-
-	var resultModel []models.ArticleQueryResult
-	processQuery := ac.DB.Raw(queryArticle, currentUser.ID, oldArticle.ID).Scan(&resultModel)
-	if processQuery.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processQuery.Error.Error()})
+	articleResponse, err := ac.findArticleByID(ctx, currentUser.ID, oldArticle.ID)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
-	} else if len(resultModel) == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+	} else if articleResponse == nil {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
-	article := resultModel[0]
-
-	tagList := make(map[string]bool)
-
-	for _, r := range resultModel {
-		if article.ID == r.ID {
-			tagList[r.TagName] = true
-		}
-	}
-
-	// Convert map to slice of keys.
-	var tagKeys []string
-	for key, _ := range tagList {
-		tagKeys = append(tagKeys, key)
-	}
-
-	articleResponse := &models.ArticleCommon{
-		Slug:           article.Slug,
-		Title:          article.Title,
-		Description:    article.Description,
-		Body:           article.Body,
-		TagList:        tagKeys,
-		CreatedAt:      article.CreatedAt,
-		UpdatedAt:      article.UpdatedAt,
-		Favorited:      article.Favorited,
-		FavoritesCount: article.FavoritesCount,
-		Author: &models.UserProfile{
-			Username:  article.Username,
-			Bio:       article.Bio,
-			Image:     article.Image,
-			Following: article.Following,
-		},
+	if err := ac.withRendering(ctx, oldArticle.ID, articleResponse); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"article": articleResponse})
@@ -650,96 +664,48 @@ func (ac *ArticleController) FavoriteArticle(ctx *gin.Context) {
 }
 
 func (ac *ArticleController) UnfavoriteArticle(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
 	currentUser := ctx.MustGet("currentUser").(models.User)
 	slug := ctx.Param("slug")
 
-	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ?`
+	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ? AND ` + articleVisibleCondition
 	var oldArticle models.Article
-	processGetArticle := ac.DB.Raw(querySingleArticle, slug).Scan(&oldArticle)
+	processGetArticle := db.Raw(querySingleArticle, slug, currentUser.ID, currentUser.ID).Scan(&oldArticle)
 	if processGetArticle.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": processGetArticle.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(processGetArticle.Error))
 		return
 	} else if oldArticle.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
-	query := `DELETE FROM user_likes WHERE id_user = ? AND id_article = ?`
-	process := ac.DB.Exec(query, currentUser.ID, oldArticle.ID)
-	if process.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": process.Error.Error()})
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := `DELETE FROM user_likes WHERE id_user = ? AND id_article = ?`
+		process := tx.Exec(query, currentUser.ID, oldArticle.ID)
+		if process.Error != nil || process.RowsAffected == 0 {
+			return process.Error
+		}
+
+		return tx.Exec(`UPDATE articles SET favorites_count = favorites_count - 1 WHERE id = ?`, oldArticle.ID).Error
+	})
+	if err != nil {
+		apperrors.Abort(ctx, dbError(err))
 		return
 	}
 
-	// construct Article Response
-	queryArticle := `
-        SELECT
-          a."id",
-		  a."slug", 
-		  a."title", 
-		  a."description", 
-		  a."body", 
-		  a."created_at", 
-		  a."updated_at", 
-		  u."username", 
-		  u."bio", 
-		  u."image", 
-		  CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following", 
-		  t."name" AS "tag_name", 
-		  CASE WHEN l."id_user" IS NULL THEN FALSE ELSE TRUE END AS "favorited",
-		  z."favorites_count"
-        FROM "articles" AS a 
-        INNER JOIN "users" AS u ON u."id" = a."id_author" 
-        LEFT JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id"
-		LEFT JOIN "article_tag" AS att ON att."id_article" = a."id" 
-        LEFT JOIN "tags" AS t ON t."id" = att."id_tag" 
-        LEFT JOIN "user_likes" AS l ON l."id_article" = a."id"
-        LEFT JOIN "users" AS ul ON ul."id" = l."id_user"
-        LEFT JOIN (SELECT y."id_article", COUNT(y."id_article") AS "favorites_count" FROM "user_likes" AS y GROUP BY y."id_article") AS z ON z."id_article" = a."id"
-        WHERE a."id" = ?`
-
-	var resultModel []models.ArticleQueryResult
-	processQuery := ac.DB.Raw(queryArticle, currentUser.ID, oldArticle.ID).Scan(&resultModel)
-	if processQuery.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": processQuery.Error.Error()})
+	articleResponse, err := ac.findArticleByID(ctx, currentUser.ID, oldArticle.ID)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
-	} else if len(resultModel) == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+	} else if articleResponse == nil {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
-	article := resultModel[0]
-
-	tagList := make(map[string]bool)
-
-	for _, r := range resultModel {
-		if article.ID == r.ID {
-			tagList[r.TagName] = true
-		}
-	}
-
-	// Convert map to slice of keys.
-	var tagKeys []string
-	for key, _ := range tagList {
-		tagKeys = append(tagKeys, key)
-	}
-
-	articleResponse := &models.ArticleCommon{
-		Slug:           article.Slug,
-		Title:          article.Title,
-		Description:    article.Description,
-		Body:           article.Body,
-		TagList:        tagKeys,
-		CreatedAt:      article.CreatedAt,
-		UpdatedAt:      article.UpdatedAt,
-		Favorited:      article.Favorited,
-		FavoritesCount: article.FavoritesCount,
-		Author: &models.UserProfile{
-			Username:  article.Username,
-			Bio:       article.Bio,
-			Image:     article.Image,
-			Following: article.Following,
-		},
+	if err := ac.withRendering(ctx, oldArticle.ID, articleResponse); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"article": articleResponse})
@@ -747,13 +713,20 @@ func (ac *ArticleController) UnfavoriteArticle(ctx *gin.Context) {
 }
 
 func (ac *ArticleController) DeleteArticle(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
 	slug := ctx.Param("slug")
 
-	query := `DELETE FROM articles WHERE slug = ?`
+	// articles go to the author's trash, the purge job removes them for good
+	query := `UPDATE articles SET deleted_at = ? WHERE slug = ? AND id_author = ? AND deleted_at IS NULL`
 
-	process := ac.DB.Exec(query, slug)
+	process := db.Exec(query, time.Now(), slug, currentUser.ID)
 	if process.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": process.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	} else if process.RowsAffected == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/markdown"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/seed"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// seededDB loads a seeded dataset of 200 users and 2000 articles into the
+// test database once. It returns the first seeded user.
+func seededDB(b *testing.B) (*gorm.DB, models.User) {
+	DB := testDB(b)
+
+	options := seed.DefaultOptions()
+	options.Seed = 41
+	options.Users = 200
+	options.ArticlesPerUser = 10
+	options.FollowsPerUser = 20
+	options.LikesPerUser = 50
+	// recent enough for the trending sort to have activity to rank
+	options.Now = time.Now().UTC().Truncate(24 * time.Hour)
+
+	data := seed.Generate(options)
+	if err := seed.Load(DB, &data); err != nil && !errors.Is(err, seed.ErrAlreadySeeded) {
+		b.Fatal(err)
+	}
+
+	var user models.User
+	if err := DB.Raw(`SELECT * FROM users WHERE username = ?`, data.Users[0].Username).Scan(&user).Error; err != nil {
+		b.Fatal(err)
+	}
+	return DB, user
+}
+
+func BenchmarkArticleListing(b *testing.B) {
+	DB, user := seededDB(b)
+
+	gin.SetMode(gin.TestMode)
+	ac := NewArticleController(DB, markdown.NewRenderer(1000))
+	engine := gin.New()
+	engine.GET("/articles", ac.GetAllArticles)
+	engine.GET("/articles/feed", func(ctx *gin.Context) { ctx.Set("currentUser", user) }, ac.GetFeedArticles)
+
+	listings := []struct {
+		name string
+		url  string
+	}{
+		{"Newest", "/articles"},
+		{"NewestPage5", "/articles?page=5"},
+		{"Favorited", "/articles?sort=favorited"},
+		{"Trending", "/articles?sort=trending"},
+		{"EstimatedCount", "/articles?count=estimate"},
+		{"Feed", "/articles/feed"},
+	}
+	for _, listing := range listings {
+		b.Run(listing.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, listing.url, nil))
+				if w.Code != http.StatusOK {
+					b.Fatalf("GET %s = %d: %s", listing.url, w.Code, w.Body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultArticleSort = "newest"
+
+// articleSort is an ordering of article listings by the SQL expression Key,
+// ties broken by id in the same direction. Numeric keys are double precision,
+// the others timestamps. Sorts whose key moves while a client pages through
+// them are OffsetOnly, a cursor would skip or repeat articles.
+type articleSort struct {
+	Key        string
+	Descending bool
+	Numeric    bool
+	OffsetOnly bool
+}
+
+const recentActivity = `now() - interval '7 days'`
+
+var articleSorts = map[string]articleSort{
+	"newest":  {Key: `a."publish_at"`, Descending: true},
+	"oldest":  {Key: `a."publish_at"`},
+	"updated": {Key: `a."updated_at"`, Descending: true},
+	"favorited": {
+		Key:        `a."favorites_count"::double precision`,
+		Descending: true,
+		Numeric:    true,
+	},
+	"commented": {
+		Key:        `a."comments_count"::double precision`,
+		Descending: true,
+		Numeric:    true,
+	},
+	// favorites and comments received over the last week, the window slides
+	// with every request
+	"trending": {
+		Key: `((SELECT COUNT(*) FROM "user_likes" AS sl WHERE sl."id_article" = a."id" AND sl."created_at" > ` + recentActivity + `)
+			+ (SELECT COUNT(*) FROM "comments" AS sc WHERE sc."id_article" = a."id" AND sc."deleted_at" IS NULL AND sc."created_at" > ` + recentActivity + `))::double precision`,
+		Descending: true,
+		Numeric:    true,
+		OffsetOnly: true,
+	},
+}
+
+// parseArticleSort reads the sort parameter and checks that cursor, if any,
+// was handed out for the same sort.
+func parseArticleSort(ctx *gin.Context, cursor *utils.Cursor) (string, []string) {
+	sort := ctx.DefaultQuery("sort", defaultArticleSort)
+	if _, ok := articleSorts[sort]; !ok {
+		return defaultArticleSort, []string{"sort must be one of newest, oldest, favorited, commented, updated or trending"}
+	}
+
+	if cursor != nil && articleSorts[sort].OffsetOnly {
+		return sort, []string{"sort " + sort + " can only be paged with page"}
+	}
+
+	return sort, checkCursor(cursor, sort, articleSorts[sort].Numeric)
+}
+
+// articleKeyset returns the keyset to read a page of articles sorted by sort.
+func articleKeyset(sort string, cursor *utils.Cursor, limit int) *keyset {
+	order := articleSorts[sort]
+	return &keyset{Sort: sort, Key: order.Key, ID: `a."id"`, Descending: order.Descending, Cursor: cursor, Limit: limit, OffsetOnly: order.OffsetOnly}
+}
+
+// articleFilter holds the listing filters. Different filters are combined
+// with AND, the values of a multi-valued filter with OR.
+type articleFilter struct {
+	Tags          []string
+	ExcludeTags   []string
+	Authors       []string
+	FavoritedBy   []string
+	From          *time.Time
+	To            *time.Time // exclusive
+	MinFavorites  int
+	Limit         int
+	Offset        int
+	Cursor        *utils.Cursor
+	Sort          string
+	EstimateCount bool
+}
+
+// parseArticleFilter reads the listing filters from the query string. Every
+// problem found is reported, not just the first one.
+func parseArticleFilter(ctx *gin.Context) (articleFilter, []string) {
+	var filter articleFilter
+	var problems []string
+
+	filter.Tags = lowerValues(queryValues(ctx, "tag"))
+	filter.ExcludeTags = lowerValues(queryValues(ctx, "excludeTag"))
+	filter.Authors = queryValues(ctx, "author")
+	filter.FavoritedBy = queryValues(ctx, "favorited")
+
+	for _, tag := range filter.Tags {
+		for _, excluded := range filter.ExcludeTags {
+			if tag == excluded {
+				problems = append(problems, fmt.Sprintf("tag %q is both required and excluded", tag))
+			}
+		}
+	}
+
+	if value, ok := ctx.GetQuery("from"); ok {
+		from, _, err := parseDateBound(value)
+		if err != nil {
+			problems = append(problems, "from "+err.Error())
+		} else {
+			filter.From = &from
+		}
+	}
+
+	if value, ok := ctx.GetQuery("to"); ok {
+		to, dateOnly, err := parseDateBound(value)
+		if err != nil {
+			problems = append(problems, "to "+err.Error())
+		} else {
+			// a bare date includes the whole day
+			if dateOnly {
+				to = to.AddDate(0, 0, 1)
+			} else {
+				to = to.Add(time.Microsecond)
+			}
+			filter.To = &to
+		}
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		problems = append(problems, "from must be before to")
+	}
+
+	if value, ok := ctx.GetQuery("minFavorites"); ok {
+		minFavorites, err := strconv.Atoi(value)
+		if err != nil || minFavorites < 0 {
+			problems = append(problems, "minFavorites must be a non-negative number")
+		} else {
+			filter.MinFavorites = minFavorites
+		}
+	}
+
+	var pageProblems []string
+	filter.Limit, filter.Offset, filter.Cursor, pageProblems = parsePage(ctx, 10)
+	problems = append(problems, pageProblems...)
+
+	var sortProblems []string
+	filter.Sort, sortProblems = parseArticleSort(ctx, filter.Cursor)
+	problems = append(problems, sortProblems...)
+
+	var countProblems []string
+	filter.EstimateCount, countProblems = parseCountMode(ctx)
+	problems = append(problems, countProblems...)
+
+	return filter, problems
+}
+
+// condition returns the WHERE clause for the filter over articles aliased a
+// joined with their author aliased u, limited to public published articles.
+func (f *articleFilter) condition() (string, []interface{}) {
+	conditions := []string{`a."deleted_at" IS NULL`, `a."status" = 'published'`, `a."visibility" = 'public'`}
+	var args []interface{}
+
+	if len(f.Authors) != 0 {
+		conditions = append(conditions, `u."username" IN ?`)
+		args = append(args, f.Authors)
+	}
+
+	if len(f.Tags) != 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM "article_tag" AS ft INNER JOIN "tags" AS t ON t."id" = ft."id_tag" WHERE ft."id_article" = a."id" AND t."name" IN ?)`)
+		args = append(args, f.Tags)
+	}
+
+	if len(f.ExcludeTags) != 0 {
+		conditions = append(conditions, `NOT EXISTS (SELECT 1 FROM "article_tag" AS ft INNER JOIN "tags" AS t ON t."id" = ft."id_tag" WHERE ft."id_article" = a."id" AND t."name" IN ?)`)
+		args = append(args, f.ExcludeTags)
+	}
+
+	if len(f.FavoritedBy) != 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM "user_likes" AS fl INNER JOIN "users" AS fu ON fu."id" = fl."id_user" WHERE fl."id_article" = a."id" AND fu."username" IN ?)`)
+		args = append(args, f.FavoritedBy)
+	}
+
+	if f.From != nil {
+		conditions = append(conditions, `a."publish_at" >= ?`)
+		args = append(args, *f.From)
+	}
+
+	if f.To != nil {
+		conditions = append(conditions, `a."publish_at" < ?`)
+		args = append(args, *f.To)
+	}
+
+	if f.MinFavorites > 0 {
+		conditions = append(conditions, `a."favorites_count" >= ?`)
+		args = append(args, f.MinFavorites)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// queryValues collects a query parameter given either repeatedly or as a
+// comma separated list, without blanks or duplicates.
+func queryValues(ctx *gin.Context, key string) []string {
+	var values []string
+	seen := make(map[string]bool)
+
+	for _, raw := range ctx.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			value = strings.TrimSpace(value)
+			if len(value) == 0 || seen[value] {
+				continue
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func lowerValues(values []string) []string {
+	for i, value := range values {
+		values[i] = strings.ToLower(value)
+	}
+	return values
+}
+
+// parseDateBound accepts either an RFC 3339 timestamp or a bare date and
+// reports which one it got.
+func parseDateBound(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+
+	return time.Time{}, false, fmt.Errorf("must be a date (2006-01-02) or an RFC 3339 timestamp, got %q", value)
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTrendingIsOnlyPagedByOffset(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/articles?sort=trending", nil)
+
+	cursor := utils.NewCursor("trending", 3.0, 7, false)
+	if _, problems := parseArticleSort(ctx, &cursor); len(problems) == 0 {
+		t.Error("a trending cursor was accepted")
+	}
+
+	ks := articleKeyset("trending", nil, 2)
+	rows := []int32{3, 2, 1}
+	page, next, prev := pageKeyset(ks, rows, true, func(id int32) (interface{}, int32) { return float64(id), id })
+	if len(page) != 2 || next != nil || prev != nil {
+		t.Errorf("pageKeyset = %v, %v, %v, want two rows and no cursors", page, next, prev)
+	}
+
+	ks = articleKeyset("newest", nil, 2)
+	if _, next, _ := pageKeyset(ks, rows, false, func(id int32) (interface{}, int32) { return float64(id), id }); next == nil {
+		t.Error("newest handed out no next cursor")
+	}
+}
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const queryRevisions = `
+	SELECT
+		r.revision,
+		r.title,
+		r.description,
+		r.body,
+		r.created_at,
+		u.username
+	FROM article_revisions AS r
+	LEFT JOIN users AS u ON u.id = r.id_author
+	WHERE r.id_article = ?`
+
+func (ac *ArticleController) GetArticleRevisions(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	slug := ctx.Param("slug")
+
+	limit, offset, problems := parseOffsetPage(ctx, 20)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+
+	article, ok := ac.findArticleIDBySlug(ctx, slug)
+	if !ok {
+		return
+	}
+
+	var resultQuery []models.ArticleRevisionQueryResult
+	process := db.Raw(queryRevisions+` ORDER BY r.revision DESC LIMIT ? OFFSET ?`, article.ID, limit, offset).Scan(&resultQuery)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	total, err := countRows(db, `SELECT id FROM article_revisions WHERE id_article = ?`, []interface{}{article.ID}, false)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	revisions := make([]models.ArticleRevisionResponse, 0)
+	for _, r := range resultQuery {
+		revisions = append(revisions, models.ArticleRevisionResponse{
+			Revision:    r.Revision,
+			Title:       r.Title,
+			Description: r.Description,
+			Author:      r.Username,
+			CreatedAt:   r.CreatedAt,
+		})
+	}
+
+	response := pageMetadata(ctx, pagination{Limit: limit, Offset: offset, Count: len(revisions), Total: &total})
+	response["revisions"] = revisions
+	response["revisionsCount"] = total
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (ac *ArticleController) GetArticleRevision(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	revisionNumber, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest("revision must be a number"))
+		return
+	}
+
+	article, ok := ac.findArticleIDBySlug(ctx, slug)
+	if !ok {
+		return
+	}
+
+	revision, ok := ac.findRevision(ctx, article.ID, revisionNumber)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"revision": &models.ArticleRevisionResponse{
+		Revision:    revision.Revision,
+		Title:       revision.Title,
+		Description: revision.Description,
+		Body:        revision.Body,
+		Author:      revision.Username,
+		CreatedAt:   revision.CreatedAt,
+	}})
+}
+
+func (ac *ArticleController) DiffArticleRevisions(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	slug := ctx.Param("slug")
+
+	article, ok := ac.findArticleIDBySlug(ctx, slug)
+	if !ok {
+		return
+	}
+
+	var latest int
+	process := db.Raw(`SELECT COALESCE(MAX(revision), 0) FROM article_revisions WHERE id_article = ?`, article.ID).Scan(&latest)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	to, errTo := strconv.Atoi(ctx.DefaultQuery("to", strconv.Itoa(latest)))
+	from, errFrom := strconv.Atoi(ctx.DefaultQuery("from", strconv.Itoa(to-1)))
+	if errTo != nil || errFrom != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest("from and to must be revision numbers"))
+		return
+	}
+
+	fromRevision, ok := ac.findRevision(ctx, article.ID, from)
+	if !ok {
+		return
+	}
+
+	toRevision, ok := ac.findRevision(ctx, article.ID, to)
+	if !ok {
+		return
+	}
+
+	fromName := fmt.Sprintf("revision %d", from)
+	toName := fmt.Sprintf("revision %d", to)
+
+	ctx.JSON(http.StatusOK, gin.H{"diff": &models.ArticleRevisionDiff{
+		From:        fromRevision.Revision,
+		To:          toRevision.Revision,
+		Title:       utils.UnifiedDiff(fromRevision.Title, toRevision.Title, fromName, toName, 3),
+		Description: utils.UnifiedDiff(fromRevision.Description, toRevision.Description, fromName, toName, 3),
+		Body:        utils.UnifiedDiff(fromRevision.Body, toRevision.Body, fromName, toName, 3),
+	}})
+}
+
+func (ac *ArticleController) RestoreArticleRevision(ctx *gin.Context) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+	slug := ctx.Param("slug")
+
+	revisionNumber, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest("revision must be a number"))
+		return
+	}
+
+	article, ok := ac.findArticleIDBySlug(ctx, slug)
+	if !ok {
+		return
+	} else if article.IDAuthor != currentUser.ID {
+		apperrors.Abort(ctx, apperrors.Forbidden("only the author can restore a revision"))
+		return
+	}
+
+	revision, ok := ac.findRevision(ctx, article.ID, revisionNumber)
+	if !ok {
+		return
+	}
+
+	// restoring never rewrites history, it records the old content as a new revision
+	queryUpdateArticle := `UPDATE articles SET title = ?, slug = ?, description = ?, body = ?, updated_at = ? WHERE id = ?`
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		processUpdate := tx.Exec(queryUpdateArticle, revision.Title, utils.GenerateSlug(revision.Title), revision.Description, revision.Body, now, article.ID)
+		if processUpdate.Error != nil {
+			return processUpdate.Error
+		}
+
+		return tx.Exec(queryInsertRevision, article.ID, currentUser.ID, revision.Title, revision.Description, revision.Body, now, article.ID).Error
+	})
+	if err != nil {
+		// the restored title can clash with the slug of another article
+		apperrors.Abort(ctx, dbError(err))
+		return
+	}
+
+	articleResponse, err := ac.findArticleByID(ctx, currentUser.ID, article.ID)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	} else if articleResponse == nil {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return
+	}
+
+	if err := ac.withRendering(ctx, article.ID, articleResponse); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"article": articleResponse})
+}
+
+func (ac *ArticleController) findArticleIDBySlug(ctx *gin.Context, slug string) (*models.Article, bool) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+	querySingleArticle := `SELECT a."id", a."id_author" FROM "articles" AS a WHERE a."slug" = ? AND ` + articleVisibleCondition
+
+	var article models.Article
+	processGetArticle := db.Raw(querySingleArticle, slug, currentUser.ID, currentUser.ID).Scan(&article)
+	if processGetArticle.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(processGetArticle.Error))
+		return nil, false
+	} else if article.ID == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return nil, false
+	}
+
+	return &article, true
+}
+
+func (ac *ArticleController) findRevision(ctx *gin.Context, articleID int32, revisionNumber int) (*models.ArticleRevisionQueryResult, bool) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	var revision models.ArticleRevisionQueryResult
+	process := db.Raw(queryRevisions+` AND r.revision = ?`, articleID, revisionNumber).Scan(&revision)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return nil, false
+	} else if revision.Revision == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound(fmt.Sprintf("revision %d not found", revisionNumber)))
+		return nil, false
+	}
+
+	return &revision, true
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/search"
+	"github.com/gin-gonic/gin"
+)
+
+func (ac *ArticleController) SearchArticles(ctx *gin.Context) {
+	// searching doesn't need an account, signed in readers also get their
+	// favorited and following flags
+	var viewerID int32
+	if currentUser, ok := ctx.Get("currentUser"); ok {
+		viewerID = currentUser.(models.User).ID
+	}
+
+	q := strings.TrimSpace(ctx.Query("q"))
+	if len(q) == 0 {
+		apperrors.Abort(ctx, apperrors.BadRequest("query parameter q is required"))
+		return
+	}
+
+	query := search.Parse(q)
+	if query.Empty() {
+		apperrors.Abort(ctx, apperrors.Validation("q has no words to search for"))
+		return
+	}
+
+	filter, problems := parseArticleFilter(ctx)
+	if _, ok := ctx.GetQuery("cursor"); ok {
+		problems = append(problems, "search results can only be paged with page")
+	}
+	if _, ok := ctx.GetQuery("sort"); ok {
+		problems = append(problems, "search results are always sorted by relevance")
+	}
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+
+	condition, args := filter.condition()
+
+	results, total, err := ac.searchFullText(ctx, query, condition, args, &filter)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	articleResponseArray := make([]models.ArticleCommon, 0)
+	if len(results) != 0 {
+		ids := make([]int32, 0, len(results))
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+
+		found, err := ac.findArticles(ctx, viewerID, `a."id" IN ?`, "", ids)
+		if err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
+		articleResponseArray = orderArticlesByID(found, ids)
+	}
+
+	byID := make(map[int32]models.ArticleSearchResult, len(results))
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+
+	for i := range articleResponseArray {
+		result := byID[articleResponseArray[i].ID]
+		articleResponseArray[i].Rank = &result.Rank
+		articleResponseArray[i].Highlight = &models.Highlight{
+			Title:       search.Mark(result.Title),
+			Description: search.Mark(result.Description),
+			Body:        search.Mark(result.Body),
+		}
+
+		if err := ac.withRendering(ctx, articleResponseArray[i].ID, &articleResponseArray[i]); err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
+	}
+
+	response := pageMetadata(ctx, pagination{Limit: filter.Limit, Offset: filter.Offset, Count: len(articleResponseArray), Total: &total})
+	response["articles"] = articleResponseArray
+	response["articlesCount"] = total
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// searchFullText runs the search with Postgres full-text search. Headlines
+// are only computed for the rows of the page.
+func (ac *ArticleController) searchFullText(ctx *gin.Context, query search.Query, condition string, args []interface{}, filter *articleFilter) ([]models.ArticleSearchResult, int64, error) {
+	db := ac.DB.WithContext(ctx.Request.Context())
+
+	queryMatching := `
+        SELECT a."id", ts_rank_cd(a."search_vector", q."query") AS "rank"
+        FROM "articles" AS a
+        INNER JOIN "users" AS u ON u."id" = a."id_author"
+        CROSS JOIN to_tsquery('english', ?) AS q("query")
+        WHERE a."search_vector" @@ q."query" AND ` + condition
+
+	queryPage := `
+        SELECT
+          p."id",
+          p."rank",
+          ts_headline('english', a."title", q."query", 'HighlightAll=true, StartSel=` + search.StartSel + `, StopSel=` + search.StopSel + `') AS "title",
+          ts_headline('english', coalesce(a."description", ''), q."query", 'HighlightAll=true, StartSel=` + search.StartSel + `, StopSel=` + search.StopSel + `') AS "description",
+          ts_headline('english', a."body", q."query", '` + search.HeadlineOptions + `') AS "body"
+        FROM (` + queryMatching + ` ORDER BY "rank" DESC, a."id" DESC LIMIT ? OFFSET ?) AS p
+        INNER JOIN "articles" AS a ON a."id" = p."id"
+        CROSS JOIN to_tsquery('english', ?) AS q("query")
+        ORDER BY p."rank" DESC, p."id" DESC`
+
+	tsQuery := query.TSQuery()
+	matchingArgs := append([]interface{}{tsQuery}, args...)
+
+	total, err := countRows(db, queryMatching, matchingArgs, filter.EstimateCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pageArgs := append(append(append([]interface{}{}, matchingArgs...), filter.Limit, filter.Offset), tsQuery)
+
+	var results []models.ArticleSearchResult
+	process := db.Raw(queryPage, pageArgs...).Scan(&results)
+	return results, total, process.Error
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/fixtures"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+)
+
+func TestArticleVisibleCondition(t *testing.T) {
+	tx := testTx(t)
+	f := fixtures.New(1, time.Now())
+
+	// usernames must not clash with whatever the database already holds
+	run := time.Now().UnixNano()
+	newUser := func(role string) models.User {
+		user := f.User("password", func(u *models.User) {
+			u.Username = fmt.Sprintf("visibility_%s_%d", role, run)
+			u.Email = u.Username + "@example.com"
+		})
+		if err := tx.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	author, follower, stranger := newUser("author"), newUser("follower"), newUser("stranger")
+	if err := tx.Create(f.UserFollow(follower, author)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	trashed := func(article *models.Article) {
+		deletedAt := time.Now()
+		article.DeletedAt = &deletedAt
+	}
+	scheduled := func(article *models.Article) {
+		publishAt := time.Now().Add(time.Hour)
+		article.Status, article.PublishAt = models.ArticleStatusScheduled, &publishAt
+	}
+	unpublished := func(article *models.Article) {
+		article.Status = models.ArticleStatusUnpublished
+	}
+
+	viewers := []struct {
+		name string
+		id   int32
+	}{{"author", author.ID}, {"follower", follower.ID}, {"stranger", stranger.ID}, {"anonymous", 0}}
+
+	// whether author, follower, stranger and anonymous see the article
+	tests := []struct {
+		name    string
+		options []func(*models.Article)
+		visible [4]bool
+	}{
+		{"public", nil, [4]bool{true, true, true, true}},
+		{"unlisted", []func(*models.Article){fixtures.Visibility(models.ArticleVisibilityUnlisted)}, [4]bool{true, true, true, true}},
+		{"followers only", []func(*models.Article){fixtures.Visibility(models.ArticleVisibilityFollowers)}, [4]bool{true, true, false, false}},
+		{"draft", []func(*models.Article){fixtures.Draft}, [4]bool{true, false, false, false}},
+		{"scheduled", []func(*models.Article){scheduled}, [4]bool{true, false, false, false}},
+		{"unpublished", []func(*models.Article){unpublished}, [4]bool{true, false, false, false}},
+		{"trashed", []func(*models.Article){trashed}, [4]bool{false, false, false, false}},
+		{"trashed followers only", []func(*models.Article){fixtures.Visibility(models.ArticleVisibilityFollowers), trashed}, [4]bool{false, false, false, false}},
+	}
+
+	for _, test := range tests {
+		article := f.Article(author, test.options...)
+		if err := tx.Create(&article).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		for i, viewer := range viewers {
+			var visible bool
+			query := `SELECT EXISTS (SELECT 1 FROM "articles" AS a WHERE a."id" = ? AND ` + articleVisibleCondition + `)`
+			if err := tx.Raw(query, article.ID, viewer.id, viewer.id).Scan(&visible).Error; err != nil {
+				t.Fatal(err)
+			}
+			if visible != test.visible[i] {
+				t.Errorf("%s article visible to %s = %v, want %v", test.name, viewer.name, visible, test.visible[i])
+			}
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package controllers
 
 import (
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type CommentController struct {
@@ -17,32 +19,40 @@ func NewCommentController(DB *gorm.DB) CommentController {
 }
 
 func (cc *CommentController) CreateComment(ctx *gin.Context) {
+	db := cc.DB.WithContext(ctx.Request.Context())
+
 	currentUser := ctx.MustGet("currentUser").(models.User)
 	slug := ctx.Param("slug")
 
 	var payload *models.CommentCreateRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
 		return
 	}
 
-	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ?`
+	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ? AND ` + articleVisibleCondition
 	var oldArticle models.Article
-	processGetArticle := cc.DB.Raw(querySingleArticle, slug).Scan(&oldArticle)
+	processGetArticle := db.Raw(querySingleArticle, slug, currentUser.ID, currentUser.ID).Scan(&oldArticle)
 	if processGetArticle.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": processGetArticle.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(processGetArticle.Error))
 		return
 	} else if oldArticle.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
 	queryInsert := `INSERT INTO comments (id_author, id_article, body) VALUES (?, ?, ?) RETURNING *`
 
 	var comment models.Comment
-	process := cc.DB.Raw(queryInsert, currentUser.ID, oldArticle.ID, payload.Comment.Body).Scan(&comment)
-	if process.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": process.Error.Error()})
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(queryInsert, currentUser.ID, oldArticle.ID, payload.Comment.Body).Scan(&comment).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`UPDATE articles SET comments_count = comments_count + 1 WHERE id = ?`, oldArticle.ID).Error
+	})
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
@@ -52,21 +62,21 @@ func (cc *CommentController) CreateComment(ctx *gin.Context) {
 			c.body, 
 			c.created_at, 
 			c.updated_at, 
-			c.id_author, 
+			COALESCE(c.id_author, 0) AS id_author, 
 			c.id_article, 
-			u.username, 
+			COALESCE(u.username, '') AS username, 
 			u.bio, 
 			u.image, 
 			CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following"
 		FROM "comments" as c 
-		INNER JOIN "users" AS u ON u."id" = c.id_author 
+		LEFT JOIN "users" AS u ON u."id" = c.id_author 
 		LEFT JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id" 
 		WHERE c.id = ?`
 
 	var commentResponse models.CommentQueryResult
-	processComment := cc.DB.Raw(queryComment, currentUser.ID, comment.ID).Scan(&commentResponse)
+	processComment := db.Raw(queryComment, currentUser.ID, comment.ID).Scan(&commentResponse)
 	if processComment.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": processComment.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(processComment.Error))
 		return
 	}
 
@@ -89,17 +99,26 @@ func (cc *CommentController) CreateComment(ctx *gin.Context) {
 }
 
 func (cc *CommentController) GetCommentsForArticle(ctx *gin.Context) {
+	db := cc.DB.WithContext(ctx.Request.Context())
+
 	currentUser := ctx.MustGet("currentUser").(models.User)
 	slug := ctx.Param("slug")
 
-	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ?`
+	limit, offset, cursor, problems := parsePage(ctx, 20)
+	problems = append(problems, checkCursor(cursor, "comments", false)...)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+
+	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ? AND ` + articleVisibleCondition
 	var oldArticle models.Article
-	processGetArticle := cc.DB.Raw(querySingleArticle, slug).Scan(&oldArticle)
+	processGetArticle := db.Raw(querySingleArticle, slug, currentUser.ID, currentUser.ID).Scan(&oldArticle)
 	if processGetArticle.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": processGetArticle.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(processGetArticle.Error))
 		return
 	} else if oldArticle.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "Data not found"})
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
@@ -109,25 +128,39 @@ func (cc *CommentController) GetCommentsForArticle(ctx *gin.Context) {
 			c.body, 
 			c.created_at, 
 			c.updated_at, 
-			c.id_author, 
+			COALESCE(c.id_author, 0) AS id_author, 
 			c.id_article, 
-			u.username, 
+			COALESCE(u.username, '') AS username, 
 			u.bio, 
 			u.image, 
-			CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following"
+			CASE WHEN f."id_user_a" IS NULL THEN FALSE ELSE TRUE END AS "following",
+			c.deleted_at
 		FROM "comments" as c 
-		INNER JOIN "users" AS u ON u."id" = c.id_author 
+		LEFT JOIN "users" AS u ON u."id" = c.id_author 
 		LEFT JOIN "user_follow" AS f ON f."id_user_a" = ? AND f."id_user_b" = u."id" 
-		WHERE c.id_article = ?
-		ORDER BY c.created_at ASC`
+		WHERE c.id_article = ?`
+	args := []interface{}{currentUser.ID, oldArticle.ID}
+
+	ks := &keyset{Sort: "comments", Key: "c.created_at", ID: "c.id", Cursor: cursor, Limit: limit}
+	keyCondition, keyArgs := ks.condition()
+	if cursor != nil {
+		offset = 0
+	}
+
+	queryComments += ` AND ` + keyCondition + ` ORDER BY ` + ks.order() + ` LIMIT ? OFFSET ?`
+	args = append(append(args, keyArgs...), limit+1, offset)
 
 	resultQuery := make([]models.CommentQueryResult, 0)
-	process := cc.DB.Raw(queryComments, currentUser.ID, oldArticle.ID).Scan(&resultQuery)
+	process := db.Raw(queryComments, args...).Scan(&resultQuery)
 	if process.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": process.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
 		return
 	}
 
+	resultQuery, nextCursor, prevCursor := pageKeyset(ks, resultQuery, offset > 0, func(c models.CommentQueryResult) (interface{}, int32) {
+		return c.CreatedAt, c.ID
+	})
+
 	commentResponseArray := make([]models.CommentResponse, 0)
 
 	for _, comment := range resultQuery {
@@ -144,22 +177,78 @@ func (cc *CommentController) GetCommentsForArticle(ctx *gin.Context) {
 			},
 		}
 
+		// keep trashed comments in place so the thread still reads in order
+		if comment.DeletedAt != nil {
+			commentObj.Body = models.CommentDeletedBody
+			commentObj.Author = nil
+		}
+
 		commentResponseArray = append(commentResponseArray, *commentObj)
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"comments": commentResponseArray})
+	// trashed comments are listed as placeholders, so they are counted too
+	commentsCount, err := countRows(db, `SELECT id FROM comments WHERE id_article = ?`, []interface{}{oldArticle.ID}, false)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	response := pageMetadata(ctx, pagination{
+		Limit:  limit,
+		Offset: offset,
+		Cursor: cursor,
+		Count:  len(commentResponseArray),
+		Total:  &commentsCount,
+		Next:   nextCursor,
+		Prev:   prevCursor,
+	})
+	response["comments"] = commentResponseArray
+	response["commentsCount"] = commentsCount
+
+	ctx.JSON(http.StatusOK, response)
 }
 
 func (cc *CommentController) DeleteCommentForArticle(ctx *gin.Context) {
-	commentId := ctx.Param("commentId")
+	db := cc.DB.WithContext(ctx.Request.Context())
 
-	commentIdInt, _ := strconv.Atoi(commentId)
+	currentUser := ctx.MustGet("currentUser").(models.User)
+	slug := ctx.Param("slug")
 
-	query := `DELETE FROM comments WHERE id = ?`
+	commentId, err := strconv.Atoi(ctx.Param("commentId"))
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest("commentId must be a number"))
+		return
+	}
 
-	process := cc.DB.Exec(query, commentIdInt)
-	if process.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": process.Error.Error()})
+	querySingleArticle := `SELECT a."id" FROM "articles" AS a WHERE a."slug" = ? AND ` + articleVisibleCondition
+	var article models.Article
+	processGetArticle := db.Raw(querySingleArticle, slug, currentUser.ID, currentUser.ID).Scan(&article)
+	if processGetArticle.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(processGetArticle.Error))
+		return
+	} else if article.ID == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return
+	}
+
+	// comments go to the author's trash, the purge job removes them for good
+	query := `UPDATE comments SET deleted_at = ? WHERE id = ? AND id_article = ? AND id_author = ? AND deleted_at IS NULL`
+
+	var deleted int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		process := tx.Exec(query, time.Now(), commentId, article.ID, currentUser.ID)
+		if process.Error != nil || process.RowsAffected == 0 {
+			return process.Error
+		}
+		deleted = process.RowsAffected
+
+		return tx.Exec(`UPDATE articles SET comments_count = comments_count - 1 WHERE id = ?`, article.ID).Error
+	})
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	} else if deleted == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
 		return
 	}
 
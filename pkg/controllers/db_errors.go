@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes a client can cause with its input.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+	pgCheckViolation      = "23514"
+	pgStringTooLong       = "22001"
+	pgInvalidText         = "22P02"
+)
+
+// dbError turns constraint violations from a failed query into client errors,
+// anything else is internal.
+func dbError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return apperrors.Internal(err)
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return apperrors.Conflict("already exists" + constraintDetail(pgErr))
+	case pgForeignKeyViolation:
+		return apperrors.Validation("refers to something that doesn't exist" + constraintDetail(pgErr))
+	case pgNotNullViolation, pgCheckViolation, pgStringTooLong, pgInvalidText:
+		return apperrors.Validation("invalid value" + constraintDetail(pgErr))
+	default:
+		return apperrors.Internal(err)
+	}
+}
+
+func constraintDetail(pgErr *pgconn.PgError) string {
+	switch {
+	case len(pgErr.ColumnName) != 0:
+		return ": " + pgErr.ColumnName
+	case len(pgErr.ConstraintName) != 0:
+		return ": " + pgErr.ConstraintName
+	}
+	return ""
+}
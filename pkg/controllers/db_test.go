@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/migrations"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/migrate"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB connects to the database named by TEST_DATABASE_DSN and migrates
+// it. Tests and benchmarks skip when it isn't set.
+func testDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		tb.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	DB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	loaded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := migrate.NewMigrator(DB, loaded).Up(0); err != nil {
+		tb.Fatal(err)
+	}
+	return DB
+}
+
+// testTx is a transaction on the test database that is rolled back when the
+// test ends, so tests leave nothing behind.
+func testTx(tb testing.TB) *gorm.DB {
+	tb.Helper()
+
+	tx := testDB(tb).Begin()
+	if tx.Error != nil {
+		tb.Fatal(tx.Error)
+	}
+	tb.Cleanup(func() { tx.Rollback() })
+	return tx
+}
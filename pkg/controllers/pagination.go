@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxPageLimit = 100
+
+// parsePage reads limit together with either page or cursor from the query
+// string.
+func parsePage(ctx *gin.Context, defaultLimit int) (int, int, *utils.Cursor, []string) {
+	var problems []string
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		problems = append(problems, fmt.Sprintf("limit must be between 1 and %d", maxPageLimit))
+	}
+
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		problems = append(problems, "page must be a positive number")
+	}
+
+	var cursor *utils.Cursor
+	if value, ok := ctx.GetQuery("cursor"); ok {
+		if _, ok := ctx.GetQuery("page"); ok {
+			problems = append(problems, "page and cursor can't be combined")
+		}
+
+		cursor, err = utils.DecodeCursor(value)
+		if err != nil {
+			problems = append(problems, "cursor is invalid")
+		}
+	}
+
+	return limit, (page - 1) * limit, cursor, problems
+}
+
+// checkCursor reports a cursor that was handed out for another list, or
+// whose key isn't the kind the list is sorted by. Cursors are only encoded,
+// so clients can forge them.
+func checkCursor(cursor *utils.Cursor, sort string, numeric bool) []string {
+	if cursor == nil {
+		return nil
+	}
+	if cursor.Sort != sort || (cursor.Number != nil) != numeric {
+		return []string{"cursor belongs to a different list"}
+	}
+	return nil
+}
+
+// parseOffsetPage reads limit and page for lists that have no stable key to
+// hand out cursors for.
+func parseOffsetPage(ctx *gin.Context, defaultLimit int) (int, int, []string) {
+	limit, offset, _, problems := parsePage(ctx, defaultLimit)
+	if _, ok := ctx.GetQuery("cursor"); ok {
+		problems = append(problems, "this list can only be paged with page")
+	}
+	return limit, offset, problems
+}
+
+// parseCountMode reads whether the client settles for an estimated total
+// instead of an exact count.
+func parseCountMode(ctx *gin.Context) (bool, []string) {
+	switch ctx.DefaultQuery("count", "exact") {
+	case "exact":
+		return false, nil
+	case "estimate":
+		return true, nil
+	default:
+		return false, []string{"count must be exact or estimate"}
+	}
+}
+
+// countRows counts the rows of query, or asks the planner for an estimate
+// when estimate is set, which avoids scanning very large tables.
+func countRows(DB *gorm.DB, query string, args []interface{}, estimate bool) (int64, error) {
+	if !estimate {
+		var total int64
+		process := DB.Raw(`SELECT COUNT(*) FROM (`+query+`) AS counted`, args...).Scan(&total)
+		return total, process.Error
+	}
+
+	var plan string
+	process := DB.Raw(`EXPLAIN (FORMAT JSON) `+query, args...).Scan(&plan)
+	if process.Error != nil {
+		return 0, process.Error
+	}
+
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil || len(explained) == 0 {
+		return 0, fmt.Errorf("could not read the query plan: %v", err)
+	}
+
+	return int64(explained[0].Plan.Rows), nil
+}
+
+// pagination describes a page of a list endpoint. Cursor is the cursor the
+// page was asked for with, Next and Prev the cursors around it if the list
+// is keyset ordered, Total the size of the whole list when it is known.
+type pagination struct {
+	Limit  int
+	Offset int
+	Cursor *utils.Cursor
+	Count  int
+	Total  *int64
+	Next   *string
+	Prev   *string
+}
+
+// pageMetadata sets the Link header for p and returns the metadata to add
+// to the response body.
+func pageMetadata(ctx *gin.Context, p pagination) gin.H {
+	hasMore := p.Next != nil
+	if p.Next == nil && p.Cursor == nil && p.Total != nil {
+		hasMore = int64(p.Offset+p.Count) < *p.Total
+	}
+
+	var page interface{}
+	current := p.Offset/p.Limit + 1
+	if p.Cursor == nil {
+		page = current
+	}
+
+	requestURL := ctx.Request.URL
+	links := []utils.Link{{URL: utils.WithQuery(requestURL, map[string]string{"page": "", "cursor": ""}), Rel: "first"}}
+
+	if p.Cursor != nil {
+		if p.Prev != nil {
+			links = append(links, utils.Link{URL: utils.WithQuery(requestURL, map[string]string{"cursor": *p.Prev}), Rel: "prev"})
+		}
+		if p.Next != nil {
+			links = append(links, utils.Link{URL: utils.WithQuery(requestURL, map[string]string{"cursor": *p.Next}), Rel: "next"})
+		}
+	} else {
+		if current > 1 {
+			links = append(links, utils.Link{URL: utils.WithQuery(requestURL, map[string]string{"page": strconv.Itoa(current - 1)}), Rel: "prev"})
+		}
+		if hasMore {
+			links = append(links, utils.Link{URL: utils.WithQuery(requestURL, map[string]string{"page": strconv.Itoa(current + 1)}), Rel: "next"})
+		}
+		if p.Total != nil {
+			last := int((*p.Total + int64(p.Limit) - 1) / int64(p.Limit))
+			if last < 1 {
+				last = 1
+			}
+			links = append(links, utils.Link{URL: utils.WithQuery(requestURL, map[string]string{"page": strconv.Itoa(last)}), Rel: "last"})
+		}
+	}
+
+	ctx.Header("Link", utils.FormatLinks(links))
+
+	return gin.H{"page": page, "limit": p.Limit, "hasMore": hasMore, "nextCursor": p.Next, "prevCursor": p.Prev}
+}
+
+// keyset pages through rows ordered by the SQL expressions Key and ID, both
+// in the same direction. Cursor is nil for the first page. Sort names the
+// ordering in the cursors handed out. No cursors are handed out when
+// OffsetOnly is set.
+type keyset struct {
+	Sort       string
+	Key        string
+	ID         string
+	Descending bool
+	Cursor     *utils.Cursor
+	Limit      int
+	OffsetOnly bool
+}
+
+// backward reports whether rows have to be read against the listing order.
+func (k *keyset) backward() bool {
+	return k.Cursor != nil && k.Cursor.Before
+}
+
+// condition limits rows to the ones past the cursor, in the direction they
+// are read.
+func (k *keyset) condition() (string, []interface{}) {
+	if k.Cursor == nil {
+		return "TRUE", nil
+	}
+
+	comparator := ">"
+	if k.Descending != k.backward() {
+		comparator = "<"
+	}
+	return "(" + k.Key + ", " + k.ID + ") " + comparator + " (?, ?)", []interface{}{k.Cursor.Key(), k.Cursor.ID}
+}
+
+// order is the ORDER BY clause to read rows with. One row more than Limit is
+// expected to be read to know whether there are more.
+func (k *keyset) order() string {
+	direction := "ASC"
+	if k.Descending != k.backward() {
+		direction = "DESC"
+	}
+	return k.Key + " " + direction + ", " + k.ID + " " + direction
+}
+
+// pageKeyset trims the rows read with k back to Limit in listing order and
+// returns the cursors of the pages around them. keyOf returns a row's key as
+// a time.Time or a float64. skipped tells whether rows
+// come before the page without a cursor, as in offset pagination.
+func pageKeyset[T any](k *keyset, rows []T, skipped bool, keyOf func(T) (interface{}, int32)) ([]T, *string, *string) {
+	more := len(rows) > k.Limit
+	if more {
+		rows = rows[:k.Limit]
+	}
+
+	if k.backward() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	hasNext, hasPrev := more, k.Cursor != nil || skipped
+	if k.backward() {
+		hasNext, hasPrev = true, more
+	}
+
+	var next, prev *string
+	if len(rows) == 0 || k.OffsetOnly {
+		return rows, next, prev
+	}
+
+	if hasNext {
+		key, id := keyOf(rows[len(rows)-1])
+		cursor := utils.EncodeCursor(utils.NewCursor(k.Sort, key, id, false))
+		next = &cursor
+	}
+
+	if hasPrev {
+		key, id := keyOf(rows[0])
+		cursor := utils.EncodeCursor(utils.NewCursor(k.Sort, key, id, true))
+		prev = &cursor
+	}
+
+	return rows, next, prev
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/middlewares"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestCheckCursor(t *testing.T) {
+	timeCursor := utils.NewCursor("comments", time.Now(), 3, false)
+	numberCursor := utils.NewCursor("favorited", 12.0, 3, false)
+	forged := utils.NewCursor("favorited", time.Now(), 3, false)
+
+	tests := []struct {
+		name    string
+		cursor  *utils.Cursor
+		sort    string
+		numeric bool
+		valid   bool
+	}{
+		{"no cursor", nil, "comments", false, true},
+		{"same list", &timeCursor, "comments", false, true},
+		{"numeric sort", &numberCursor, "favorited", true, true},
+		{"other list", &numberCursor, "comments", false, false},
+		{"other list with a time key", &timeCursor, "followers", false, false},
+		{"forged key kind", &forged, "favorited", true, false},
+	}
+
+	for _, test := range tests {
+		if problems := checkCursor(test.cursor, test.sort, test.numeric); (len(problems) == 0) != test.valid {
+			t.Errorf("%s: checkCursor = %v, want valid %v", test.name, problems, test.valid)
+		}
+	}
+}
+
+// Cursors are checked before any query runs, so the handlers need no
+// database to refuse one.
+func TestListsRefuseCursorsOfOtherLists(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	DB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=db.invalid"}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments := NewCommentController(DB)
+	users := UserController{DB: DB}
+	engine := gin.New()
+	engine.Use(middlewares.RenderErrors(), func(ctx *gin.Context) { ctx.Set("currentUser", models.User{ID: 1}) })
+	engine.GET("/articles/:slug/comments", comments.GetCommentsForArticle)
+	engine.GET("/profiles/:profileUsername/followers", users.GetFollowers)
+	engine.GET("/profiles/:profileUsername/following", users.GetFollowing)
+
+	favorited := utils.EncodeCursor(utils.NewCursor("favorited", 12.0, 3, false))
+	followers := utils.EncodeCursor(utils.NewCursor("followers", time.Now(), 3, false))
+
+	for _, url := range []string{
+		"/articles/a/comments?cursor=" + favorited,
+		"/articles/a/comments?cursor=" + followers,
+		"/profiles/a/followers?cursor=" + favorited,
+		"/profiles/a/following?cursor=" + followers,
+	} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("GET %s = %d, want 422: %s", url, w.Code, w.Body)
+		}
+	}
+}
@@ -16,10 +16,12 @@ func NewTagController(DB *gorm.DB) TagController {
 }
 
 func (tc *TagController) GetTags(ctx *gin.Context) {
+	db := tc.DB.WithContext(ctx.Request.Context())
+
 	var rawTags []models.Tag
 	var normalizedTags []string
 
-	tc.DB.Raw("SELECT * FROM tags").Scan(&rawTags)
+	db.Raw("SELECT * FROM tags").Scan(&rawTags)
 
 	for _, t := range rawTags {
 		normalizedTags = append(normalizedTags, t.Name)
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TrashController struct {
+	DB        *gorm.DB
+	Retention time.Duration
+}
+
+func NewTrashController(DB *gorm.DB, Retention time.Duration) TrashController {
+	return TrashController{DB, Retention}
+}
+
+// GetTrash lists the trashed articles and comments side by side, page and
+// limit apply to both lists.
+func (tc *TrashController) GetTrash(ctx *gin.Context) {
+	db := tc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+	cutoff := time.Now().Add(-tc.Retention)
+
+	limit, offset, problems := parseOffsetPage(ctx, 20)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+
+	queryArticles := `
+		SELECT a.slug, a.title, a.deleted_at
+		FROM articles AS a
+		WHERE a.id_author = ? AND a.deleted_at > ?`
+	queryComments := `
+		SELECT c.id, a.slug AS article_slug, c.body, c.deleted_at
+		FROM comments AS c
+		INNER JOIN articles AS a ON a.id = c.id_article
+		WHERE c.id_author = ? AND c.deleted_at > ?`
+	args := []interface{}{currentUser.ID, cutoff}
+
+	articlesCount, err := countRows(db, queryArticles, args, false)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	commentsCount, err := countRows(db, queryComments, args, false)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	articles := make([]models.TrashArticle, 0)
+	process := db.Raw(queryArticles+` ORDER BY a.deleted_at DESC, a.id DESC LIMIT ? OFFSET ?`, currentUser.ID, cutoff, limit, offset).Scan(&articles)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	comments := make([]models.TrashComment, 0)
+	process = db.Raw(queryComments+` ORDER BY c.deleted_at DESC, c.id DESC LIMIT ? OFFSET ?`, currentUser.ID, cutoff, limit, offset).Scan(&comments)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	for i := range articles {
+		articles[i].PurgeAt = articles[i].DeletedAt.Add(tc.Retention)
+	}
+
+	for i := range comments {
+		comments[i].PurgeAt = comments[i].DeletedAt.Add(tc.Retention)
+	}
+
+	// the pages run out with the longer of the two lists
+	total, count := articlesCount, len(articles)
+	if commentsCount > total {
+		total, count = commentsCount, len(comments)
+	}
+
+	response := pageMetadata(ctx, pagination{Limit: limit, Offset: offset, Count: count, Total: &total})
+	response["articles"] = articles
+	response["articlesCount"] = articlesCount
+	response["comments"] = comments
+	response["commentsCount"] = commentsCount
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (tc *TrashController) RestoreArticle(ctx *gin.Context) {
+	db := tc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+	slug := ctx.Param("slug")
+
+	query := `UPDATE articles SET deleted_at = NULL WHERE slug = ? AND id_author = ? AND deleted_at > ?`
+
+	process := db.Exec(query, slug, currentUser.ID, time.Now().Add(-tc.Retention))
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	} else if process.RowsAffected == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (tc *TrashController) RestoreComment(ctx *gin.Context) {
+	db := tc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	commentId, err := strconv.Atoi(ctx.Param("commentId"))
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest("commentId must be a number"))
+		return
+	}
+
+	// a comment only comes back under an article that isn't trashed itself
+	query := `
+		UPDATE comments AS c SET deleted_at = NULL
+		FROM articles AS a
+		WHERE c.id = ? AND c.id_author = ? AND c.deleted_at > ?
+			AND a.id = c.id_article AND a.deleted_at IS NULL
+		RETURNING c.id_article`
+
+	var restored []int32
+	err = db.Transaction(func(tx *gorm.DB) error {
+		process := tx.Raw(query, commentId, currentUser.ID, time.Now().Add(-tc.Retention)).Scan(&restored)
+		if process.Error != nil || len(restored) == 0 {
+			return process.Error
+		}
+
+		return tx.Exec(`UPDATE articles SET comments_count = comments_count + 1 WHERE id = ?`, restored[0]).Error
+	})
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	if len(restored) == 0 {
+		var articleTrashed bool
+		queryTrashed := `
+			SELECT EXISTS (
+				SELECT 1 FROM comments AS c
+				INNER JOIN articles AS a ON a.id = c.id_article
+				WHERE c.id = ? AND c.id_author = ? AND c.deleted_at > ? AND a.deleted_at IS NOT NULL
+			)`
+		process := db.Raw(queryTrashed, commentId, currentUser.ID, time.Now().Add(-tc.Retention)).Scan(&articleTrashed)
+		if process.Error != nil {
+			apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		} else if articleTrashed {
+			apperrors.Abort(ctx, apperrors.Conflict("the comment's article is in the trash, restore the article first"))
+		} else {
+			apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/storage"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	avatarSize            = 512
+	avatarThumbSize       = 128
+	articleImageSize      = 1600
+	articleImageThumbSize = 400
+)
+
+type UploadController struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+	Config  *configs.Config
+}
+
+func NewUploadController(DB *gorm.DB, Storage storage.Storage, Config *configs.Config) UploadController {
+	return UploadController{DB, Storage, Config}
+}
+
+func (upc *UploadController) UploadAvatar(ctx *gin.Context) {
+	db := upc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+	token := ctx.MustGet("token").(string)
+
+	config := upc.Config
+
+	data, ok := upc.readImage(ctx, config.UploadMaxSize)
+	if !ok {
+		return
+	}
+
+	upload, ok := upc.storeImage(ctx, config.MediaBaseURL, fmt.Sprintf("avatars/%d", currentUser.ID), data, avatarSize, avatarThumbSize, true)
+	if !ok {
+		return
+	}
+
+	var updatedUser models.User
+	result := db.Raw("UPDATE users SET image = ?, updated_at = ? WHERE id = ? RETURNING *", upload.URL, time.Now(), currentUser.ID).Scan(&updatedUser)
+	if result.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(result.Error))
+		return
+	}
+
+	userResponse := &models.UserResponse{
+		User: &models.UserCommon{
+			Email:    updatedUser.Email,
+			Username: updatedUser.Username,
+			Bio:      updatedUser.Bio,
+			Image:    updatedUser.Image,
+			Token:    token,
+		},
+	}
+
+	ctx.JSON(http.StatusOK, userResponse)
+}
+
+func (upc *UploadController) UploadArticleImage(ctx *gin.Context) {
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	config := upc.Config
+
+	data, ok := upc.readImage(ctx, config.UploadMaxSize)
+	if !ok {
+		return
+	}
+
+	upload, ok := upc.storeImage(ctx, config.MediaBaseURL, fmt.Sprintf("images/%d", currentUser.ID), data, articleImageSize, articleImageThumbSize, false)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.UploadResponse{Upload: upload})
+}
+
+func (upc *UploadController) ServeMedia(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	reader, object, err := upc.Storage.Get(ctx.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		apperrors.Abort(ctx, apperrors.NotFound("Data not found"))
+		return
+	} else if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+	defer reader.Close()
+
+	// keys are derived from the content hash, so they never change
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.DataFromReader(http.StatusOK, object.Size, object.ContentType, reader, nil)
+}
+
+// readImage reads the "image" multipart field, enforcing maxSize.
+func (upc *UploadController) readImage(ctx *gin.Context, maxSize int64) ([]byte, bool) {
+	// leave room for the multipart envelope around the file itself
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize+1<<20)
+
+	fileHeader, err := ctx.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			apperrors.Abort(ctx, apperrors.TooLarge(fmt.Sprintf("file exceeds the %d bytes limit", maxSize)))
+			return nil, false
+		}
+		apperrors.Abort(ctx, apperrors.BadRequest("multipart field image is required"))
+		return nil, false
+	}
+
+	if fileHeader.Size > maxSize {
+		apperrors.Abort(ctx, apperrors.TooLarge(fmt.Sprintf("file exceeds the %d bytes limit", maxSize)))
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize))
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
+		return nil, false
+	}
+
+	if _, err := utils.SniffImageType(data); err != nil {
+		apperrors.Abort(ctx, apperrors.UnsupportedMediaType(err.Error()))
+		return nil, false
+	}
+
+	return data, true
+}
+
+// storeImage resizes the image, stores it with a thumbnail under prefix and
+// returns the public URLs.
+func (upc *UploadController) storeImage(ctx *gin.Context, baseURL string, prefix string, data []byte, size int, thumbSize int, square bool) (*models.Upload, bool) {
+	resized, err := utils.ResizeImage(data, size, square)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Validation(err.Error()))
+		return nil, false
+	}
+
+	thumb, err := utils.ResizeImage(data, thumbSize, true)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Validation(err.Error()))
+		return nil, false
+	}
+
+	sum := sha256.Sum256(resized.Data)
+	name := prefix + "/" + hex.EncodeToString(sum[:12])
+	key := name + resized.Extension
+	thumbKey := name + "_thumb" + thumb.Extension
+
+	if err := upc.Storage.Put(ctx.Request.Context(), key, resized.Data, resized.ContentType); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return nil, false
+	}
+
+	if err := upc.Storage.Put(ctx.Request.Context(), thumbKey, thumb.Data, thumb.ContentType); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return nil, false
+	}
+
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	return &models.Upload{
+		URL:          baseURL + "/" + key,
+		ThumbnailURL: baseURL + "/" + thumbKey,
+		ContentType:  resized.ContentType,
+		Size:         len(resized.Data),
+		Width:        resized.Width,
+		Height:       resized.Height,
+	}, true
+}
@@ -1,67 +1,130 @@
 package controllers
 
 import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/storage"
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+const queryProfileByUsername = `
+	SELECT
+		u.username,
+		u.bio,
+		u.image,
+		CASE WHEN f.id_user_a IS NULL THEN FALSE ELSE TRUE END AS following,
+		(SELECT COUNT(*) FROM user_follow AS fr WHERE fr.id_user_b = u.id) AS followers_count,
+		(SELECT COUNT(*) FROM user_follow AS fg WHERE fg.id_user_a = u.id) AS following_count,
+		(SELECT COUNT(*) FROM articles AS a WHERE a.id_author = u.id AND a.deleted_at IS NULL AND a.status = 'published' AND a.visibility = 'public') AS articles_count
+	FROM users AS u
+	LEFT JOIN user_follow AS f ON f.id_user_a = ? AND f.id_user_b = u.id
+	WHERE u.username = ?`
+
+var errInvalidInviteCode = errors.New("invite code is invalid, expired or already used")
+
 type UserController struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Storage storage.Storage
+	Config  *configs.Config
 }
 
-func NewUserController(DB *gorm.DB) UserController {
-	return UserController{DB}
+func NewUserController(DB *gorm.DB, Storage storage.Storage, Config *configs.Config) UserController {
+	return UserController{DB, Storage, Config}
 }
 
 func (uc *UserController) RegisterUser(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
 	var payload *models.UserRegisterRequest
 
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
 		return
 	}
 
 	hashedPassword, err := utils.HashPassword(payload.User.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
+	config := uc.Config
+
 	newUser := &models.User{
 		Username: payload.User.Username,
 		Email:    payload.User.Email,
 		Password: hashedPassword,
+		Status:   models.UserStatusActive,
+	}
+
+	if config.RegistrationMode == configs.RegistrationModeApproval {
+		newUser.Status = models.UserStatusPending
+	}
+
+	if config.RegistrationMode == configs.RegistrationModeInvite && len(payload.User.InviteCode) == 0 {
+		apperrors.Abort(ctx, apperrors.Forbidden("an invite code is required to register"))
+		return
 	}
 
-	result := uc.DB.Raw("INSERT INTO users (username, email, password) VALUES (?, ?, ?) RETURNING *", newUser.Username, newUser.Email, newUser.Password).Scan(&newUser)
+	queryConsumeInvite := `UPDATE invite_codes SET uses = uses + 1, updated_at = ?
+		WHERE code = ? AND (max_uses = 0 OR uses < max_uses) AND (expires_at IS NULL OR expires_at > ?)
+		RETURNING *`
+	queryInsert := `INSERT INTO users (username, email, password, status) VALUES (?, ?, ?, ?) RETURNING *`
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if config.RegistrationMode == configs.RegistrationModeInvite {
+			now := time.Now()
+			var invite models.InviteCode
+			processInvite := tx.Raw(queryConsumeInvite, now, strings.TrimSpace(payload.User.InviteCode), now).Scan(&invite)
+			if processInvite.Error != nil {
+				return processInvite.Error
+			} else if invite.ID == 0 {
+				return errInvalidInviteCode
+			}
+		}
 
-	fmt.Println(newUser)
+		return tx.Raw(queryInsert, newUser.Username, newUser.Email, newUser.Password, newUser.Status).Scan(&newUser).Error
+	})
 
-	if result.Error != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": result.Error.Error()})
+	if errors.Is(err, errInvalidInviteCode) {
+		apperrors.Abort(ctx, apperrors.Forbidden(err.Error()))
+		return
+	} else if err != nil {
+		// a taken username or email is a conflict, not a server error
+		apperrors.Abort(ctx, dbError(err))
 		return
 	}
 
-	config, _ := configs.LoadConfig(".")
+	if newUser.Status == models.UserStatusPending {
+		ctx.JSON(http.StatusAccepted, gin.H{"status": "pending", "message": "registration received, your account is waiting for admin approval"})
+		return
+	}
 
 	// Generate Tokens
 	accessToken, err := utils.CreateToken(config.AccessTokenExpiresIn, newUser.ID, config.AccessTokenPrivateKey)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
 	refreshToken, err := utils.CreateToken(config.RefreshTokenExpiresIn, newUser.ID, config.RefreshTokenPrivateKey)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
@@ -83,38 +146,50 @@ func (uc *UserController) RegisterUser(ctx *gin.Context) {
 }
 
 func (uc *UserController) LoginUser(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
 	var payload *models.UserLoginRequest
 
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
 		return
 	}
 
 	var user models.User
-	result := uc.DB.Raw("SELECT * FROM users WHERE email = ?", strings.ToLower(payload.User.Email)).Scan(&user)
+	result := db.Raw("SELECT * FROM users WHERE email = ?", strings.ToLower(payload.User.Email)).Scan(&user)
 
 	if result.Error != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": "Invalid email or Password"})
+		apperrors.Abort(ctx, apperrors.Internal(result.Error))
 		return
 	}
 
 	if err := utils.VerifyPassword(user.Password, payload.User.Password); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": "Invalid email or Password"})
+		apperrors.Abort(ctx, apperrors.BadRequest("Invalid email or Password"))
 		return
 	}
 
-	config, _ := configs.LoadConfig(".")
+	if user.Status == models.UserStatusPending {
+		apperrors.Abort(ctx, apperrors.Forbidden("your account is waiting for admin approval"))
+		return
+	}
+
+	if user.Status == models.UserStatusDisabled {
+		apperrors.Abort(ctx, apperrors.Forbidden("this account has been disabled"))
+		return
+	}
+
+	config := uc.Config
 
 	// Generate Tokens
 	accessToken, err := utils.CreateToken(config.AccessTokenExpiresIn, user.ID, config.AccessTokenPrivateKey)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
 	refreshToken, err := utils.CreateToken(config.RefreshTokenExpiresIn, user.ID, config.RefreshTokenPrivateKey)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
@@ -136,40 +211,48 @@ func (uc *UserController) LoginUser(ctx *gin.Context) {
 }
 
 func (uc *UserController) RefreshToken(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
 	message := "could not refresh access token"
 
 	cookie, err := ctx.Cookie("refresh_token")
 
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"status": "fail", "message": message})
+		apperrors.Abort(ctx, apperrors.Forbidden(message))
 		return
 	}
 
-	config, _ := configs.LoadConfig(".")
+	config := uc.Config
 
 	sub, err := utils.ValidateToken(cookie, config.RefreshTokenPublicKey)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Forbidden(err.Error()))
 		return
 	}
 
 	var user models.User
-	result := uc.DB.Raw("SELECT * FROM users WHERE id = ?", fmt.Sprint(sub)).Scan(&user)
+	result := db.Raw("SELECT * FROM users WHERE id = ?", fmt.Sprint(sub)).Scan(&user)
 	if result.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"status": "fail", "message": "the user belonging to this token no longer exists"})
+		apperrors.Abort(ctx, apperrors.Internal(result.Error))
+		return
+	} else if user.ID == 0 {
+		apperrors.Abort(ctx, apperrors.Forbidden("the user belonging to this token no longer exists"))
+		return
+	} else if user.Status != models.UserStatusActive {
+		apperrors.Abort(ctx, apperrors.Forbidden("this account is not active"))
 		return
 	}
 
 	// Generate Tokens
 	accessToken, err := utils.CreateToken(config.AccessTokenExpiresIn, user.ID, config.AccessTokenPrivateKey)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
 	refreshToken, err := utils.CreateToken(config.RefreshTokenExpiresIn, user.ID, config.RefreshTokenPrivateKey)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(err))
 		return
 	}
 
@@ -216,13 +299,15 @@ func (uc *UserController) GetCurrentUser(ctx *gin.Context) {
 }
 
 func (uc *UserController) UpdateCurrentUser(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
 	currentUser := ctx.MustGet("currentUser").(models.User)
 	token := ctx.MustGet("token").(string)
 
 	var payload *models.UserUpdateRequest
 
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "fail", "message": err.Error()})
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
 		return
 	}
 
@@ -232,7 +317,7 @@ func (uc *UserController) UpdateCurrentUser(ctx *gin.Context) {
 		var err error
 		hashedPassword, err = utils.HashPassword(*(payload.User.Password))
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			apperrors.Abort(ctx, apperrors.Internal(err))
 			return
 		}
 	}
@@ -259,10 +344,10 @@ func (uc *UserController) UpdateCurrentUser(ctx *gin.Context) {
 
 	now := time.Now()
 	var updatedUser models.User
-	result := uc.DB.Raw("UPDATE users SET email = ?, password = ?, username = ?, bio = ?, image = ?, updated_at = ? WHERE id = ? RETURNING *", strings.ToLower(newEmail), hashedPassword, newUsername, utils.NewNullString(newBio), utils.NewNullString(newImage), now, currentUser.ID).Scan(&updatedUser)
+	result := db.Raw("UPDATE users SET email = ?, password = ?, username = ?, bio = ?, image = ?, updated_at = ? WHERE id = ? RETURNING *", strings.ToLower(newEmail), hashedPassword, newUsername, utils.NewNullString(newBio), utils.NewNullString(newImage), now, currentUser.ID).Scan(&updatedUser)
 
 	if result.Error != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": result.Error.Error()})
+		apperrors.Abort(ctx, apperrors.Internal(result.Error))
 		return
 	}
 
@@ -280,23 +365,18 @@ func (uc *UserController) UpdateCurrentUser(ctx *gin.Context) {
 }
 
 func (uc *UserController) GetProfile(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
 	profileUsername := ctx.Param("profileUsername")
 	currentUser := ctx.MustGet("currentUser").(models.User)
 
-	query :=
-		`SELECT 
-			u.username, 
-			u.bio, 
-			u.image, 
-			CASE WHEN f.id_user_a IS NULL THEN FALSE ELSE TRUE END AS following 
-		FROM users AS u 
-		LEFT JOIN user_follow AS f ON f.id_user_a = ? AND f.id_user_b = u.id 
-		WHERE u.username = ?`
-
 	var profile models.UserProfile
-	queryProfileResult := uc.DB.Raw(query, currentUser.ID, profileUsername).Scan(&profile)
-	if queryProfileResult.Error != nil || len(profile.Username) == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "profile not found"})
+	queryProfileResult := db.Raw(queryProfileByUsername, currentUser.ID, profileUsername).Scan(&profile)
+	if queryProfileResult.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(queryProfileResult.Error))
+		return
+	} else if len(profile.Username) == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("profile not found"))
 		return
 	}
 
@@ -308,38 +388,43 @@ func (uc *UserController) GetProfile(ctx *gin.Context) {
 }
 
 func (uc *UserController) FollowUser(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
 	profileUsername := ctx.Param("profileUsername")
 	currentUser := ctx.MustGet("currentUser").(models.User)
 
-	queryInsert := `INSERT INTO user_follow VALUES (?, ?)`
+	// following twice is a no-op
+	queryInsert := `INSERT INTO user_follow (id_user_a, id_user_b) VALUES (?, ?) ON CONFLICT DO NOTHING`
 	queryUser := `SELECT id FROM users WHERE username = ?`
-	queryProfile :=
-		`SELECT 
-			u.username, 
-			u.bio, 
-			u.image, 
-			CASE WHEN f.id_user_a IS NULL THEN FALSE ELSE TRUE END AS following 
-		FROM users AS u 
-		LEFT JOIN user_follow AS f ON f.id_user_a = ? AND f.id_user_b = u.id 
-		WHERE u.username = ?`
 
 	var user models.User
-	queryUserResult := uc.DB.Raw(queryUser, profileUsername).Scan(&user)
-	if queryUserResult.Error != nil || user.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "profile not found"})
+	queryUserResult := db.Raw(queryUser, profileUsername).Scan(&user)
+	if queryUserResult.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(queryUserResult.Error))
+		return
+	} else if user.ID == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("profile not found"))
 		return
 	}
 
-	execFollow := uc.DB.Exec(queryInsert, currentUser.ID, user.ID)
+	if user.ID == currentUser.ID {
+		apperrors.Abort(ctx, apperrors.Validation("you cannot follow yourself"))
+		return
+	}
+
+	execFollow := db.Exec(queryInsert, currentUser.ID, user.ID)
 	if execFollow.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": execFollow.Error.Error()})
+		apperrors.Abort(ctx, dbError(execFollow.Error))
 		return
 	}
 
 	var profile models.UserProfile
-	queryProfileResult := uc.DB.Raw(queryProfile, currentUser.ID, profileUsername).Scan(&profile)
-	if queryProfileResult.Error != nil || len(profile.Username) == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "profile not found"})
+	queryProfileResult := db.Raw(queryProfileByUsername, currentUser.ID, profileUsername).Scan(&profile)
+	if queryProfileResult.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(queryProfileResult.Error))
+		return
+	} else if len(profile.Username) == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("profile not found"))
 		return
 	}
 
@@ -351,38 +436,37 @@ func (uc *UserController) FollowUser(ctx *gin.Context) {
 }
 
 func (uc *UserController) UnfollowUser(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
 	profileUsername := ctx.Param("profileUsername")
 	currentUser := ctx.MustGet("currentUser").(models.User)
 
 	queryDelete := `DELETE FROM user_follow WHERE id_user_a = ? AND id_user_b = ?`
 	queryUser := `SELECT id FROM users WHERE username = ?`
-	queryProfile :=
-		`SELECT 
-			u.username, 
-			u.bio, 
-			u.image, 
-			CASE WHEN f.id_user_a IS NULL THEN FALSE ELSE TRUE END AS following 
-		FROM users AS u 
-		LEFT JOIN user_follow AS f ON f.id_user_a = ? AND f.id_user_b = u.id 
-		WHERE u.username = ?`
 
 	var user models.User
-	queryUserResult := uc.DB.Raw(queryUser, profileUsername).Scan(&user)
-	if queryUserResult.Error != nil || user.ID == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "profile not found"})
+	queryUserResult := db.Raw(queryUser, profileUsername).Scan(&user)
+	if queryUserResult.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(queryUserResult.Error))
+		return
+	} else if user.ID == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("profile not found"))
 		return
 	}
 
-	execUnfollow := uc.DB.Exec(queryDelete, currentUser.ID, user.ID)
+	execUnfollow := db.Exec(queryDelete, currentUser.ID, user.ID)
 	if execUnfollow.Error != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "fail", "message": execUnfollow.Error.Error()})
+		apperrors.Abort(ctx, dbError(execUnfollow.Error))
 		return
 	}
 
 	var profile models.UserProfile
-	queryProfileResult := uc.DB.Raw(queryProfile, currentUser.ID, profileUsername).Scan(&profile)
-	if queryProfileResult.Error != nil || len(profile.Username) == 0 {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "fail", "message": "profile not found"})
+	queryProfileResult := db.Raw(queryProfileByUsername, currentUser.ID, profileUsername).Scan(&profile)
+	if queryProfileResult.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(queryProfileResult.Error))
+		return
+	} else if len(profile.Username) == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("profile not found"))
 		return
 	}
 
@@ -392,3 +476,539 @@ func (uc *UserController) UnfollowUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, userProfileResponse)
 }
+
+func (uc *UserController) DeleteCurrentUser(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	var payload *models.UserDeleteRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		apperrors.Abort(ctx, apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	if err := utils.VerifyPassword(currentUser.Password, payload.User.Password); err != nil {
+		apperrors.Abort(ctx, apperrors.Unauthorized("Invalid Password"))
+		return
+	}
+
+	config := uc.Config
+
+	queryOwnArticles := `SELECT id FROM articles WHERE id_author = ?`
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		queryUnfavorite := `UPDATE articles SET favorites_count = favorites_count - 1 WHERE id IN (SELECT id_article FROM user_likes WHERE id_user = ?)`
+		if err := tx.Exec(queryUnfavorite, currentUser.ID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`DELETE FROM user_likes WHERE id_user = ?`, currentUser.ID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`DELETE FROM user_follow WHERE id_user_a = ? OR id_user_b = ?`, currentUser.ID, currentUser.ID).Error; err != nil {
+			return err
+		}
+
+		if config.AccountDeletionPolicy == configs.AccountDeletionAnonymize {
+			// keep articles and comments readable, attributed to a tombstone account
+			tombstone := fmt.Sprintf("deleted-user-%d", currentUser.ID)
+			queryAnonymize := `UPDATE users SET username = ?, email = ?, password = '', bio = NULL, image = NULL, status = ?, updated_at = ? WHERE id = ?`
+			return tx.Exec(queryAnonymize, tombstone, tombstone+"@deleted.invalid", models.UserStatusDeleted, time.Now(), currentUser.ID).Error
+		}
+
+		cascade := []struct {
+			query string
+			args  []interface{}
+		}{
+			{`DELETE FROM user_likes WHERE id_article IN (` + queryOwnArticles + `)`, []interface{}{currentUser.ID}},
+			{`UPDATE articles AS a SET comments_count = a.comments_count - c.removed
+			FROM (SELECT id_article, COUNT(*) AS removed FROM comments WHERE id_author = ? AND deleted_at IS NULL GROUP BY id_article) AS c
+			WHERE a.id = c.id_article`, []interface{}{currentUser.ID}},
+			{`DELETE FROM comments WHERE id_author = ? OR id_article IN (` + queryOwnArticles + `)`, []interface{}{currentUser.ID, currentUser.ID}},
+			{`DELETE FROM article_tag WHERE id_article IN (` + queryOwnArticles + `)`, []interface{}{currentUser.ID}},
+			{`DELETE FROM articles WHERE id_author = ?`, []interface{}{currentUser.ID}},
+			{`DELETE FROM users WHERE id = ?`, []interface{}{currentUser.ID}},
+		}
+
+		for _, statement := range cascade {
+			if err := tx.Exec(statement.query, statement.args...).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	ctx.SetCookie("access_token", "", -1, "/", "localhost", false, true)
+	ctx.SetCookie("refresh_token", "", -1, "/", "localhost", false, true)
+	ctx.SetCookie("logged_in", "", -1, "/", "localhost", false, false)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (uc *UserController) ExportCurrentUser(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	queryArticles := `
+		SELECT
+			a.id,
+			a.slug,
+			a.title,
+			a.description,
+			a.body,
+			a.status,
+			a.visibility,
+			a.created_at,
+			a.updated_at,
+			a.favorites_count
+		FROM articles AS a
+		WHERE a.id_author = ?
+		ORDER BY a.created_at ASC`
+	queryArticleTags := `
+		SELECT att.id_article, t.name
+		FROM article_tag AS att
+		INNER JOIN tags AS t ON t.id = att.id_tag
+		INNER JOIN articles AS a ON a.id = att.id_article
+		WHERE a.id_author = ?
+		ORDER BY t.name ASC`
+	queryComments := `
+		SELECT c.id, a.slug AS article_slug, c.body, c.created_at, c.updated_at
+		FROM comments AS c
+		INNER JOIN articles AS a ON a.id = c.id_article
+		WHERE c.id_author = ?
+		ORDER BY c.created_at ASC`
+	queryRevisions := `
+		SELECT a.slug AS article_slug, r.revision, r.title, r.description, r.body, r.created_at
+		FROM article_revisions AS r
+		INNER JOIN articles AS a ON a.id = r.id_article
+		WHERE r.id_author = ?
+		ORDER BY a.slug ASC, r.revision ASC`
+	queryFavorites := `
+		SELECT a.slug
+		FROM user_likes AS l
+		INNER JOIN articles AS a ON a.id = l.id_article
+		WHERE l.id_user = ?
+		ORDER BY a.slug ASC`
+	queryFollowing := `
+		SELECT u.username
+		FROM user_follow AS f
+		INNER JOIN users AS u ON u.id = f.id_user_b
+		WHERE f.id_user_a = ?
+		ORDER BY u.username ASC`
+	queryFollowers := `
+		SELECT u.username
+		FROM user_follow AS f
+		INNER JOIN users AS u ON u.id = f.id_user_a
+		WHERE f.id_user_b = ?
+		ORDER BY u.username ASC`
+
+	articles := make([]models.ExportArticle, 0)
+	articleTags := make([]models.ExportArticleTag, 0)
+	comments := make([]models.ExportComment, 0)
+	revisions := make([]models.ExportRevision, 0)
+	favorites := make([]string, 0)
+	following := make([]string, 0)
+	followers := make([]string, 0)
+
+	queries := []struct {
+		query string
+		dest  interface{}
+	}{
+		{queryArticles, &articles},
+		{queryArticleTags, &articleTags},
+		{queryComments, &comments},
+		{queryRevisions, &revisions},
+		{queryFavorites, &favorites},
+		{queryFollowing, &following},
+		{queryFollowers, &followers},
+	}
+
+	for _, q := range queries {
+		if process := db.Raw(q.query, currentUser.ID).Scan(q.dest); process.Error != nil {
+			apperrors.Abort(ctx, apperrors.Internal(process.Error))
+			return
+		}
+	}
+
+	tagsByArticle := make(map[int32][]string)
+	for _, t := range articleTags {
+		tagsByArticle[t.IDArticle] = append(tagsByArticle[t.IDArticle], t.Name)
+	}
+
+	for i := range articles {
+		articles[i].TagList = tagsByArticle[articles[i].ID]
+		if articles[i].TagList == nil {
+			articles[i].TagList = make([]string, 0)
+		}
+	}
+
+	// uploads aren't tracked in the database, they live under the user's
+	// prefixes in the storage
+	uploads := make([]models.ExportUpload, 0)
+	baseURL := strings.TrimRight(uc.Config.MediaBaseURL, "/")
+	for _, prefix := range []string{"avatars", "images"} {
+		objects, err := uc.Storage.List(ctx.Request.Context(), fmt.Sprintf("%s/%d/", prefix, currentUser.ID))
+		if err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
+
+		for _, object := range objects {
+			uploads = append(uploads, models.ExportUpload{
+				Key:         object.Key,
+				URL:         baseURL + "/" + object.Key,
+				ContentType: object.ContentType,
+				Size:        object.Size,
+			})
+		}
+	}
+
+	profile := &models.ExportProfile{
+		Username:  currentUser.Username,
+		Email:     currentUser.Email,
+		Bio:       currentUser.Bio,
+		Image:     currentUser.Image,
+		Role:      currentUser.Role,
+		CreatedAt: currentUser.CreatedAt,
+		UpdatedAt: currentUser.UpdatedAt,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	entries := []struct {
+		name string
+		v    interface{}
+	}{
+		{"profile.json", profile},
+		{"articles.json", articles},
+		{"comments.json", comments},
+		{"revisions.json", revisions},
+		{"uploads.json", uploads},
+		{"favorites.json", favorites},
+		{"following.json", following},
+		{"followers.json", followers},
+	}
+
+	for _, e := range entries {
+		if err := utils.WriteZipJSON(zw, e.name, e.v); err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
+	}
+
+	for _, article := range articles {
+		var md strings.Builder
+		md.WriteString("---\n")
+		md.WriteString("title: " + strconv.Quote(article.Title) + "\n")
+		md.WriteString("description: " + strconv.Quote(article.Description) + "\n")
+		tags := make([]string, 0, len(article.TagList))
+		for _, tag := range article.TagList {
+			tags = append(tags, strconv.Quote(tag))
+		}
+		md.WriteString("tags: [" + strings.Join(tags, ", ") + "]\n")
+		md.WriteString("status: " + article.Status + "\n")
+		md.WriteString("visibility: " + article.Visibility + "\n")
+		md.WriteString("createdAt: " + article.CreatedAt.Format(time.RFC3339) + "\n")
+		md.WriteString("updatedAt: " + article.UpdatedAt.Format(time.RFC3339) + "\n")
+		md.WriteString("---\n\n")
+		md.WriteString(article.Body + "\n")
+
+		if err := utils.WriteZipFile(zw, "articles/"+article.Slug+".md", []byte(md.String())); err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
+	}
+
+	for _, upload := range uploads {
+		data, err := uc.readUpload(ctx, upload.Key)
+		if err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
+
+		if err := utils.WriteZipFile(zw, "uploads/"+upload.Key, data); err != nil {
+			apperrors.Abort(ctx, apperrors.Internal(err))
+			return
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		apperrors.Abort(ctx, apperrors.Internal(err))
+		return
+	}
+
+	filename := fmt.Sprintf("%s-export-%s.zip", currentUser.Username, time.Now().Format("20060102"))
+	// usernames may hold quotes and semicolons, FormatMediaType escapes them
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+func (uc *UserController) readUpload(ctx *gin.Context, key string) ([]byte, error) {
+	reader, _, err := uc.Storage.Get(ctx.Request.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+func (uc *UserController) GetFollowers(ctx *gin.Context) {
+	uc.listFollows(ctx, "followers", "id_user_b", "id_user_a")
+}
+
+func (uc *UserController) GetFollowing(ctx *gin.Context) {
+	uc.listFollows(ctx, "following", "id_user_a", "id_user_b")
+}
+
+// listFollows lists the users found in listColumn of user_follow rows whose
+// matchColumn is the requested profile. list names it in the cursors.
+func (uc *UserController) listFollows(ctx *gin.Context, list string, matchColumn string, listColumn string) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
+	profileUsername := ctx.Param("profileUsername")
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	limit, offset, cursor, problems := parsePage(ctx, 20)
+	problems = append(problems, checkCursor(cursor, list, false)...)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+
+	if cursor != nil {
+		offset = 0
+	}
+
+	ks := &keyset{Sort: list, Key: "f.created_at", ID: "u.id", Descending: true, Cursor: cursor, Limit: limit}
+	keyCondition, keyArgs := ks.condition()
+
+	queryUser := `SELECT id FROM users WHERE username = ?`
+	queryCount := `SELECT COUNT(*) FROM user_follow WHERE ` + matchColumn + ` = ?`
+	queryList := `
+		SELECT
+			u.username,
+			u.bio,
+			u.image,
+			CASE WHEN vf.id_user_a IS NULL THEN FALSE ELSE TRUE END AS following,
+			u.id,
+			f.created_at
+		FROM user_follow AS f
+		INNER JOIN users AS u ON u.id = f.` + listColumn + `
+		LEFT JOIN user_follow AS vf ON vf.id_user_a = ? AND vf.id_user_b = u.id
+		WHERE f.` + matchColumn + ` = ? AND ` + keyCondition + `
+		ORDER BY ` + ks.order() + `
+		LIMIT ?
+		OFFSET ?`
+
+	var user models.User
+	queryUserResult := db.Raw(queryUser, profileUsername).Scan(&user)
+	if queryUserResult.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(queryUserResult.Error))
+		return
+	} else if user.ID == 0 {
+		apperrors.Abort(ctx, apperrors.NotFound("profile not found"))
+		return
+	}
+
+	var profilesCount int64
+	processCount := db.Raw(queryCount, user.ID).Scan(&profilesCount)
+	if processCount.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(processCount.Error))
+		return
+	}
+
+	args := append(append([]interface{}{currentUser.ID, user.ID}, keyArgs...), limit+1, offset)
+
+	rows := make([]models.UserFollowProfile, 0)
+	processList := db.Raw(queryList, args...).Scan(&rows)
+	if processList.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(processList.Error))
+		return
+	}
+
+	rows, nextCursor, prevCursor := pageKeyset(ks, rows, offset > 0, func(r models.UserFollowProfile) (interface{}, int32) {
+		return r.CreatedAt, r.ID
+	})
+
+	profiles := make([]models.UserProfile, 0, len(rows))
+	for _, r := range rows {
+		profiles = append(profiles, r.UserProfile)
+	}
+
+	response := pageMetadata(ctx, pagination{
+		Limit:  limit,
+		Offset: offset,
+		Cursor: cursor,
+		Count:  len(profiles),
+		Total:  &profilesCount,
+		Next:   nextCursor,
+		Prev:   prevCursor,
+	})
+	response["profiles"] = profiles
+	response["profilesCount"] = profilesCount
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (uc *UserController) SearchProfiles(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+	q := strings.TrimSpace(ctx.Query("q"))
+
+	if len(q) == 0 {
+		apperrors.Abort(ctx, apperrors.BadRequest("query parameter q is required"))
+		return
+	}
+
+	// results are ranked by relevance, which has no stable key for a cursor
+	if _, ok := ctx.GetQuery("cursor"); ok {
+		apperrors.Abort(ctx, apperrors.Validation("search results can only be paged with page"))
+		return
+	}
+
+	limit, offset, _, problems := parsePage(ctx, 20)
+	if len(problems) != 0 {
+		apperrors.Abort(ctx, apperrors.Validation(problems...))
+		return
+	}
+	prefix := utils.EscapeLike(q) + "%"
+	contains := "%" + utils.EscapeLike(q) + "%"
+
+	// prefix and substring matches first, then trigram (fuzzy) matches on
+	// username and on words of the bio
+	whereSearch := `
+		WHERE u.status = @active
+		AND (
+			u.username ILIKE @prefix ESCAPE '\'
+			OR u.bio ILIKE @contains ESCAPE '\'
+			OR u.username % @q
+			OR @q <% u.bio
+		)`
+	queryCount := `SELECT COUNT(*) FROM users AS u ` + whereSearch
+	querySearch := `
+		SELECT
+			u.username,
+			u.bio,
+			u.image,
+			CASE WHEN f.id_user_a IS NULL THEN FALSE ELSE TRUE END AS following
+		FROM users AS u
+		LEFT JOIN user_follow AS f ON f.id_user_a = @viewer AND f.id_user_b = u.id
+		` + whereSearch + `
+		ORDER BY
+			CASE WHEN u.username ILIKE @prefix ESCAPE '\' THEN 0 ELSE 1 END,
+			GREATEST(similarity(u.username, @q), word_similarity(@q, COALESCE(u.bio, ''))) DESC,
+			u.username ASC
+		LIMIT @limit
+		OFFSET @offset`
+
+	args := []interface{}{
+		sql.Named("active", models.UserStatusActive),
+		sql.Named("viewer", currentUser.ID),
+		sql.Named("q", q),
+		sql.Named("prefix", prefix),
+		sql.Named("contains", contains),
+		sql.Named("limit", limit),
+		sql.Named("offset", offset),
+	}
+
+	var profilesCount int64
+	processCount := db.Raw(queryCount, args...).Scan(&profilesCount)
+	if processCount.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(processCount.Error))
+		return
+	}
+
+	profiles := make([]models.UserProfile, 0)
+	processSearch := db.Raw(querySearch, args...).Scan(&profiles)
+	if processSearch.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(processSearch.Error))
+		return
+	}
+
+	response := pageMetadata(ctx, pagination{Limit: limit, Offset: offset, Count: len(profiles), Total: &profilesCount})
+	response["profiles"] = profiles
+	response["profilesCount"] = profilesCount
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (uc *UserController) GetSuggestedAuthors(ctx *gin.Context) {
+	db := uc.DB.WithContext(ctx.Request.Context())
+
+	currentUser := ctx.MustGet("currentUser").(models.User)
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		apperrors.Abort(ctx, apperrors.BadRequest("limit must be a positive number"))
+		return
+	} else if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// candidates are ranked by the tags they share with what the viewer writes
+	// and favorites, then by how many of the viewer's follows follow them
+	query := `
+		WITH viewer_tags AS (
+			SELECT att.id_tag
+			FROM article_tag AS att
+			INNER JOIN articles AS a ON a.id = att.id_article
+			WHERE a.id_author = @viewer
+			UNION
+			SELECT att.id_tag
+			FROM article_tag AS att
+			INNER JOIN user_likes AS l ON l.id_article = att.id_article
+			WHERE l.id_user = @viewer
+		),
+		tag_scores AS (
+			SELECT a.id_author AS id_user, COUNT(DISTINCT att.id_tag) AS shared_tags
+			FROM articles AS a
+			INNER JOIN article_tag AS att ON att.id_article = a.id
+			WHERE a.deleted_at IS NULL AND a.status = 'published' AND a.visibility = 'public' AND att.id_tag IN (SELECT id_tag FROM viewer_tags)
+			GROUP BY a.id_author
+		),
+		network_scores AS (
+			SELECT f2.id_user_b AS id_user, COUNT(*) AS mutual_follows
+			FROM user_follow AS f1
+			INNER JOIN user_follow AS f2 ON f2.id_user_a = f1.id_user_b
+			WHERE f1.id_user_a = @viewer
+			GROUP BY f2.id_user_b
+		)
+		SELECT
+			u.username,
+			u.bio,
+			u.image,
+			FALSE AS following,
+			COALESCE(ts.shared_tags, 0) AS shared_tags,
+			COALESCE(ns.mutual_follows, 0) AS mutual_follows
+		FROM users AS u
+		LEFT JOIN tag_scores AS ts ON ts.id_user = u.id
+		LEFT JOIN network_scores AS ns ON ns.id_user = u.id
+		WHERE u.id <> @viewer
+		AND u.status = @active
+		AND (ts.shared_tags IS NOT NULL OR ns.mutual_follows IS NOT NULL)
+		AND NOT EXISTS (SELECT 1 FROM user_follow AS f WHERE f.id_user_a = @viewer AND f.id_user_b = u.id)
+		ORDER BY
+			COALESCE(ts.shared_tags, 0) * 2 + COALESCE(ns.mutual_follows, 0) DESC,
+			u.username ASC
+		LIMIT @limit`
+
+	suggestions := make([]models.UserSuggestion, 0)
+	process := db.Raw(query, sql.Named("viewer", currentUser.ID), sql.Named("active", models.UserStatusActive), sql.Named("limit", limit)).Scan(&suggestions)
+	if process.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(process.Error))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"profiles": suggestions})
+}
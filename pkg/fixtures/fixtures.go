@@ -0,0 +1,175 @@
+// Package fixtures builds realistic pkg/models values for seeding and tests.
+// A Factory created with the same seed always builds the same values.
+package fixtures
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+)
+
+// Factory builds models. Values that have to be unique, like usernames and
+// slugs, carry a sequence number. Timestamps fall in the 90 days before Now.
+type Factory struct {
+	Now time.Time
+
+	rand     *rand.Rand
+	sequence int
+}
+
+func New(seed int64, now time.Time) *Factory {
+	return &Factory{Now: now, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Rand is the factory's source of randomness, for choices it doesn't make.
+func (f *Factory) Rand() *rand.Rand {
+	return f.rand
+}
+
+func (f *Factory) next() int {
+	f.sequence++
+	return f.sequence
+}
+
+// Time returns a moment between after and Now.
+func (f *Factory) Time(after time.Time) time.Time {
+	if !after.Before(f.Now) {
+		return f.Now
+	}
+	return after.Add(time.Duration(f.rand.Int63n(int64(f.Now.Sub(after))))).Truncate(time.Second)
+}
+
+func (f *Factory) past() time.Time {
+	return f.Time(f.Now.AddDate(0, 0, -90))
+}
+
+// User builds an active account. The password is stored as given, hash it
+// first if the account has to log in.
+func (f *Factory) User(password string, options ...func(*models.User)) models.User {
+	n := f.next()
+	first, last := pick(f, firstNames), pick(f, lastNames)
+	username := fmt.Sprintf("%s_%s%d", first, last, n)
+	bio := f.Sentence(8 + f.rand.Intn(10))
+	createdAt := f.past()
+
+	user := models.User{
+		Username:  username,
+		Email:     username + "@example.com",
+		Password:  password,
+		Bio:       &bio,
+		Role:      models.UserRoleUser,
+		Status:    models.UserStatusActive,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	for _, option := range options {
+		option(&user)
+	}
+	return user
+}
+
+// Article builds a published public article by author, written after the
+// author signed up.
+func (f *Factory) Article(author models.User, options ...func(*models.Article)) models.Article {
+	n := f.next()
+	title := f.Title()
+	createdAt := f.Time(author.CreatedAt)
+	updatedAt := createdAt
+	if f.rand.Intn(4) == 0 {
+		updatedAt = f.Time(createdAt)
+	}
+	publishAt := createdAt
+
+	article := models.Article{
+		IDAuthor:    author.ID,
+		Slug:        fmt.Sprintf("%s-%d", utils.GenerateSlug(title), n),
+		Title:       title,
+		Description: strings.TrimSuffix(f.Sentence(6+f.rand.Intn(8)), "."),
+		Body:        f.Markdown(),
+		Status:      models.ArticleStatusPublished,
+		PublishAt:   &publishAt,
+		Visibility:  models.ArticleVisibilityPublic,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+	}
+	for _, option := range options {
+		option(&article)
+	}
+	return article
+}
+
+// Draft makes an article an unpublished draft.
+func Draft(article *models.Article) {
+	article.Status = models.ArticleStatusDraft
+	article.PublishAt = nil
+}
+
+// Visibility sets who can see an article.
+func Visibility(visibility string) func(*models.Article) {
+	return func(article *models.Article) {
+		article.Visibility = visibility
+	}
+}
+
+// Revision builds the revision holding the current content of article.
+func (f *Factory) Revision(article models.Article, revision int32) models.ArticleRevision {
+	author := article.IDAuthor
+	return models.ArticleRevision{
+		IDArticle:   article.ID,
+		Revision:    revision,
+		IDAuthor:    &author,
+		Title:       article.Title,
+		Description: article.Description,
+		Body:        article.Body,
+		CreatedAt:   article.UpdatedAt,
+	}
+}
+
+func (f *Factory) Tag(name string) models.Tag {
+	return models.Tag{Name: name, CreatedAt: f.Now, UpdatedAt: f.Now}
+}
+
+func (f *Factory) ArticleTag(article models.Article, tag models.Tag) models.ArticleTag {
+	return models.ArticleTag{IDArticle: article.ID, IDTag: tag.ID}
+}
+
+// Comment builds a comment by author on article, written after both exist.
+func (f *Factory) Comment(author models.User, article models.Article, options ...func(*models.Comment)) models.Comment {
+	after := article.CreatedAt
+	if author.CreatedAt.After(after) {
+		after = author.CreatedAt
+	}
+	createdAt := f.Time(after)
+
+	comment := models.Comment{
+		IDAuthor:  author.ID,
+		IDArticle: article.ID,
+		Body:      f.Paragraph(),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	for _, option := range options {
+		option(&comment)
+	}
+	return comment
+}
+
+func (f *Factory) UserFollow(follower models.User, followed models.User) models.UserFollow {
+	after := follower.CreatedAt
+	if followed.CreatedAt.After(after) {
+		after = followed.CreatedAt
+	}
+	return models.UserFollow{IDUserA: follower.ID, IDUserB: followed.ID, CreatedAt: f.Time(after)}
+}
+
+func (f *Factory) UserLike(user models.User, article models.Article) models.UserLike {
+	after := article.CreatedAt
+	if user.CreatedAt.After(after) {
+		after = user.CreatedAt
+	}
+	return models.UserLike{IDUser: user.ID, IDArticle: article.ID, CreatedAt: f.Time(after)}
+}
@@ -0,0 +1,73 @@
+package fixtures
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+)
+
+var now = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFactoryIsDeterministic(t *testing.T) {
+	build := func() []interface{} {
+		f := New(7, now)
+		author := f.User("password")
+		reader := f.User("password")
+		article := f.Article(author)
+		return []interface{}{author, reader, article, f.Comment(reader, article), f.UserLike(reader, article), f.UserFollow(reader, author)}
+	}
+
+	if first, second := build(), build(); !reflect.DeepEqual(first, second) {
+		t.Errorf("the same seed built different values:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestFactoryKeepsEventsInOrder(t *testing.T) {
+	f := New(3, now)
+	for i := 0; i < 100; i++ {
+		author := f.User("password")
+		reader := f.User("password")
+		article := f.Article(author)
+		comment := f.Comment(reader, article)
+		like := f.UserLike(reader, article)
+
+		switch {
+		case author.CreatedAt.Before(now.AddDate(0, 0, -90)) || author.CreatedAt.After(now):
+			t.Fatalf("user created at %v, outside the 90 days before %v", author.CreatedAt, now)
+		case article.CreatedAt.Before(author.CreatedAt) || article.UpdatedAt.Before(article.CreatedAt):
+			t.Fatalf("article %+v written before its author signed up at %v", article, author.CreatedAt)
+		case comment.CreatedAt.Before(article.CreatedAt) || comment.CreatedAt.Before(reader.CreatedAt):
+			t.Fatalf("comment at %v predates the article or its author", comment.CreatedAt)
+		case like.CreatedAt.Before(article.CreatedAt) || like.CreatedAt.After(now):
+			t.Fatalf("like at %v outside the article's lifetime", like.CreatedAt)
+		}
+	}
+}
+
+func TestArticleOptions(t *testing.T) {
+	f := New(1, now)
+	author := f.User("password")
+	author.ID = 4
+
+	draft := f.Article(author, Draft, Visibility(models.ArticleVisibilityFollowers))
+	if draft.Status != models.ArticleStatusDraft || draft.PublishAt != nil || draft.Visibility != models.ArticleVisibilityFollowers {
+		t.Errorf("draft = %s, %v, %s", draft.Status, draft.PublishAt, draft.Visibility)
+	}
+	if draft.IDAuthor != author.ID {
+		t.Errorf("IDAuthor = %d, want %d", draft.IDAuthor, author.ID)
+	}
+
+	draft.ID = 9
+	tag := f.Tag(f.TagName())
+	tag.ID = 2
+	if articleTag := f.ArticleTag(draft, tag); articleTag.IDArticle != 9 || articleTag.IDTag != 2 {
+		t.Errorf("ArticleTag = %+v", articleTag)
+	}
+
+	revision := f.Revision(draft, 1)
+	if revision.IDArticle != 9 || *revision.IDAuthor != author.ID || revision.Body != draft.Body || !revision.CreatedAt.Equal(draft.UpdatedAt) {
+		t.Errorf("Revision = %+v", revision)
+	}
+}
@@ -0,0 +1,99 @@
+package fixtures
+
+import (
+	"strings"
+)
+
+var (
+	firstNames = []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi", "ivan", "judy", "mallory", "niaj", "olivia", "peggy", "rupert", "sybil", "trent", "victor", "walter", "yara"}
+	lastNames  = []string{"anders", "baker", "chen", "diaz", "evans", "fischer", "garcia", "hughes", "ito", "jensen", "kowalski", "lopez", "muller", "nakamura", "okafor", "patel", "quinn", "rossi", "silva", "tanaka"}
+
+	adjectives = []string{"practical", "gentle", "modern", "hidden", "simple", "honest", "fast", "careful", "surprising", "boring", "scalable", "small", "forgotten", "opinionated", "pragmatic"}
+	nouns      = []string{"guide", "introduction", "look", "tour", "case", "story", "checklist", "lesson", "primer", "field report", "retrospective", "deep dive"}
+	subjects   = []string{"go", "postgres", "kubernetes", "testing", "caching", "search", "api design", "code review", "databases", "observability", "concurrency", "security", "rust", "css", "accessibility"}
+
+	words = strings.Fields(`the a of to and in is it that for on with as was be at by this have from or
+		one had not but what all were when we there can an your which their said if do will each about
+		how up out them then she many some so these would other into has more her two like him see time
+		could no make than first been its who now people my made over did down only way find use may
+		water long little very after words called just where most know get through back much before go
+		good new write our used me man too any day same right look think also around another came come
+		work three word must because does part even place well such here take why things help put years
+		different away again off went old number great tell men say small every found still between name
+		should home big give air line set own under read last never us left end along while might next
+		sound below saw something thought both few those always looked show large often together asked
+		house world going want school important until form food keep children feet land side without
+		boy once animals life enough took sometimes four head above kind began almost live page got
+		earth need far hand high year mother light parts country father let night following picture
+		being study second eyes soon times story boys since white days ever paper hard near sentence
+		better best across during today others however sure means knew try told young miles sun ways
+		thing whole hear example heard several change answer room sea against top turned learn point
+		city play toward five using himself usually query index schema request response server client
+		cache latency throughput deploy release branch commit review module package interface struct`)
+)
+
+// Word returns a random common word.
+func (f *Factory) Word() string {
+	return words[f.rand.Intn(len(words))]
+}
+
+// Sentence returns a capitalized sentence of n words.
+func (f *Factory) Sentence(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = f.Word()
+	}
+	sentence := strings.Join(parts, " ")
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+// Paragraph returns between 3 and 7 sentences.
+func (f *Factory) Paragraph() string {
+	sentences := make([]string, 3+f.rand.Intn(5))
+	for i := range sentences {
+		sentences[i] = f.Sentence(6 + f.rand.Intn(12))
+	}
+	return strings.Join(sentences, " ")
+}
+
+// Markdown returns an article body: paragraphs under a few headings, with
+// the occasional list or code block.
+func (f *Factory) Markdown() string {
+	var b strings.Builder
+	sections := 2 + f.rand.Intn(3)
+	for i := 0; i < sections; i++ {
+		if i > 0 {
+			b.WriteString("## " + strings.TrimSuffix(f.Sentence(2+f.rand.Intn(3)), ".") + "\n\n")
+		}
+
+		for p := 1 + f.rand.Intn(3); p > 0; p-- {
+			b.WriteString(f.Paragraph() + "\n\n")
+		}
+
+		switch f.rand.Intn(4) {
+		case 0:
+			for item := 2 + f.rand.Intn(3); item > 0; item-- {
+				b.WriteString("- " + f.Sentence(3+f.rand.Intn(5)) + "\n")
+			}
+			b.WriteString("\n")
+		case 1:
+			b.WriteString("```\n" + f.Word() + " := " + f.Word() + "(" + f.Word() + ")\n```\n\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Title returns an article title.
+func (f *Factory) Title() string {
+	title := "The " + pick(f, adjectives) + " " + pick(f, nouns) + " to " + pick(f, subjects)
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// TagName returns one of the subjects articles are about.
+func (f *Factory) TagName() string {
+	return strings.ReplaceAll(pick(f, subjects), " ", "-")
+}
+
+func pick(f *Factory, values []string) string {
+	return values[f.rand.Intn(len(values))]
+}
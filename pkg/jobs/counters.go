@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CounterReconciler periodically repairs the favorites and comments counters
+// of articles that drifted from the rows they count.
+type CounterReconciler struct {
+	runner
+
+	DB       *gorm.DB
+	Interval time.Duration
+}
+
+func NewCounterReconciler(DB *gorm.DB, Interval time.Duration) *CounterReconciler {
+	return &CounterReconciler{DB: DB, Interval: Interval}
+}
+
+// Start runs the reconciler in the background until Stop is called.
+func (cr *CounterReconciler) Start() {
+	cr.start(cr.Interval, func() {
+		if _, err := cr.Reconcile(); err != nil {
+			log.Println("counter reconciler:", err)
+		}
+	})
+}
+
+// Reconcile recounts favorites and live comments of every article and
+// returns how many articles had to be fixed.
+func (cr *CounterReconciler) Reconcile() (int64, error) {
+	query := `
+		UPDATE articles AS a SET
+			favorites_count = c.favorites_count,
+			comments_count = c.comments_count
+		FROM (
+			SELECT
+				a.id,
+				(SELECT COUNT(*) FROM user_likes AS l WHERE l.id_article = a.id) AS favorites_count,
+				(SELECT COUNT(*) FROM comments AS m WHERE m.id_article = a.id AND m.deleted_at IS NULL) AS comments_count
+			FROM articles AS a
+		) AS c
+		WHERE a.id = c.id AND (a.favorites_count <> c.favorites_count OR a.comments_count <> c.comments_count)`
+
+	var fixed int64
+	err := unbounded(cr.DB, func(tx *gorm.DB) error {
+		process := tx.Exec(query)
+		fixed = process.RowsAffected
+		return process.Error
+	})
+	return fixed, err
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrashPurger periodically removes articles and comments that have been in
+// the trash for longer than Retention.
+type TrashPurger struct {
+	runner
+
+	DB        *gorm.DB
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func NewTrashPurger(DB *gorm.DB, Retention time.Duration, Interval time.Duration) *TrashPurger {
+	return &TrashPurger{DB: DB, Retention: Retention, Interval: Interval}
+}
+
+// Start runs the purger in the background until Stop is called.
+func (tp *TrashPurger) Start() {
+	tp.start(tp.Interval, func() {
+		if _, err := tp.Purge(time.Now()); err != nil {
+			log.Println("trash purger:", err)
+		}
+	})
+}
+
+// Purge hard deletes articles trashed before now minus the retention and
+// strips comments trashed as long ago down to tombstones, which keep their
+// place in the thread without a body or an author. It returns how many rows
+// were purged.
+func (tp *TrashPurger) Purge(now time.Time) (int64, error) {
+	cutoff := now.Add(-tp.Retention)
+
+	var purged int64
+	err := unbounded(tp.DB, func(tx *gorm.DB) error {
+		queries := []string{
+			`UPDATE comments SET body = '', id_author = NULL WHERE deleted_at <= ? AND id_author IS NOT NULL`,
+			`DELETE FROM articles WHERE deleted_at <= ?`,
+		}
+
+		for _, query := range queries {
+			process := tx.Exec(query, cutoff)
+			if process.Error != nil {
+				return process.Error
+			}
+			purged += process.RowsAffected
+		}
+		return nil
+	})
+
+	return purged, err
+}
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// runner calls a function right away and then on every tick until stopped.
+type runner struct {
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func (r *runner) start(interval time.Duration, run func()) {
+	r.stop = make(chan struct{})
+	r.done.Add(1)
+
+	go func() {
+		defer r.done.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run()
+
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop signals the job and waits for the current run to finish.
+func (r *runner) Stop() {
+	close(r.stop)
+	r.done.Wait()
+}
+
+// unbounded runs fn in a transaction exempt from the per-query deadline the
+// connections are opened with, for jobs that go through whole tables.
+func unbounded(DB *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`SET LOCAL statement_timeout = 0`).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"gorm.io/gorm"
+)
+
+// PublishScheduler periodically publishes scheduled articles whose publish
+// time has passed.
+type PublishScheduler struct {
+	runner
+
+	DB       *gorm.DB
+	Interval time.Duration
+}
+
+func NewPublishScheduler(DB *gorm.DB, Interval time.Duration) *PublishScheduler {
+	return &PublishScheduler{DB: DB, Interval: Interval}
+}
+
+// Start runs the scheduler in the background until Stop is called.
+func (ps *PublishScheduler) Start() {
+	ps.start(ps.Interval, func() {
+		if _, err := ps.PublishDue(time.Now()); err != nil {
+			log.Println("publish scheduler:", err)
+		}
+	})
+}
+
+// PublishDue publishes every scheduled article due at now and returns how
+// many were published.
+func (ps *PublishScheduler) PublishDue(now time.Time) (int64, error) {
+	query := `UPDATE articles SET status = ? WHERE status = ? AND publish_at <= ?`
+
+	process := ps.DB.Exec(query, models.ArticleStatusPublished, models.ArticleStatusScheduled, now)
+	return process.RowsAffected, process.Error
+}
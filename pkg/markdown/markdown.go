@@ -0,0 +1,235 @@
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const wordsPerMinute = 200
+
+type Rendered struct {
+	HTML        string
+	TOC         []models.TOCEntry
+	WordCount   int
+	ReadingTime int
+}
+
+// Renderer turns article Markdown into sanitized HTML. Results are kept in a
+// small LRU cache, so callers should pass a key that changes with every
+// revision of the source.
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type cacheEntry struct {
+	key      string
+	rendered *Rendered
+}
+
+func NewRenderer(capacity int) *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle("github"),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(util.Prioritized(headingAnchors{}, 1000)),
+		),
+	)
+
+	// raw HTML in the source is already dropped by goldmark, the policy is
+	// the second line of defence and keeps the highlighter's classes
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^[\w\s-]+$`)).OnElements("a", "code", "pre", "span", "div")
+	policy.AllowAttrs("tabindex").Matching(regexp.MustCompile(`^0$`)).OnElements("pre")
+
+	return &Renderer{
+		md:       md,
+		policy:   policy,
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (r *Renderer) Render(key string, source string) (*Rendered, error) {
+	if rendered, ok := r.get(key); ok {
+		return rendered, nil
+	}
+
+	src := []byte(source)
+	doc := r.md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := r.md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+
+	wordCount := countWords(doc, src)
+	readingTime := 0
+	if wordCount > 0 {
+		readingTime = (wordCount + wordsPerMinute - 1) / wordsPerMinute
+	}
+
+	rendered := &Rendered{
+		HTML:        r.policy.Sanitize(buf.String()),
+		TOC:         tableOfContents(doc, src),
+		WordCount:   wordCount,
+		ReadingTime: readingTime,
+	}
+
+	r.put(key, rendered)
+	return rendered, nil
+}
+
+func (r *Renderer) get(key string) (*Rendered, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[key]
+	if !ok {
+		return nil, false
+	}
+	r.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).rendered, true
+}
+
+func (r *Renderer) put(key string, rendered *Rendered) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, ok := r.entries[key]; ok {
+		element.Value.(*cacheEntry).rendered = rendered
+		r.order.MoveToFront(element)
+		return
+	}
+
+	r.entries[key] = r.order.PushFront(&cacheEntry{key, rendered})
+	for r.capacity > 0 && r.order.Len() > r.capacity {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func tableOfContents(doc ast.Node, src []byte) []models.TOCEntry {
+	toc := make([]models.TOCEntry, 0)
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		toc = append(toc, models.TOCEntry{
+			Level: heading.Level,
+			ID:    string(idBytes),
+			Text:  headingText(heading, src),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+
+	return toc
+}
+
+func headingText(heading *ast.Heading, src []byte) string {
+	var buf bytes.Buffer
+	for c := heading.FirstChild(); c != nil; c = c.NextSibling() {
+		if link, ok := c.(*ast.Link); ok && isAnchor(link) {
+			continue
+		}
+		buf.Write(c.Text(src))
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// countWords counts the words of prose, leaving code blocks out because they
+// are skimmed rather than read.
+func countWords(doc ast.Node, src []byte) int {
+	count := 0
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Link:
+			if isAnchor(node) {
+				return ast.WalkSkipChildren, nil
+			}
+		case *ast.Text:
+			count += len(strings.FieldsFunc(string(node.Segment.Value(src)), func(r rune) bool {
+				return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '\'' && r != '-')
+			}))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return count
+}
+
+var anchorClass = []byte("anchor")
+
+// headingAnchors appends a self link to every heading that has an id.
+type headingAnchors struct{}
+
+func (headingAnchors) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		id, ok := heading.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+
+		link := ast.NewLink()
+		link.Destination = append([]byte("#"), id.([]byte)...)
+		link.SetAttributeString("class", anchorClass)
+		link.AppendChild(link, ast.NewString([]byte("#")))
+		heading.AppendChild(heading, link)
+
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+func isAnchor(link *ast.Link) bool {
+	class, ok := link.AttributeString("class")
+	if !ok {
+		return false
+	}
+	classBytes, _ := class.([]byte)
+	return bytes.Equal(classBytes, anchorClass)
+}
@@ -2,50 +2,87 @@ package middlewares
 
 import (
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func DeserializeUser() gin.HandlerFunc {
+// DeserializeUser authenticates the request with the access token from the
+// Authorization header or the access_token cookie.
+func DeserializeUser(DB *gorm.DB, config *configs.Config) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var accessToken string
-		cookie, err := ctx.Cookie("access_token")
-
-		authorizationHeader := ctx.Request.Header.Get("Authorization")
-		fields := strings.Fields(authorizationHeader)
+		accessToken := readAccessToken(ctx)
+		if accessToken == "" {
+			apperrors.Abort(ctx, apperrors.Unauthorized("You are not logged in"))
+			return
+		}
 
-		if len(fields) != 0 && fields[0] == "Token" {
-			accessToken = fields[1]
-		} else if err == nil {
-			accessToken = cookie
+		if authenticate(ctx, DB, config, accessToken) {
+			ctx.Next()
 		}
+	}
+}
 
+// OptionalUser authenticates the request like DeserializeUser when it
+// carries an access token and lets it through anonymously otherwise.
+// Handlers find no currentUser on anonymous requests.
+func OptionalUser(DB *gorm.DB, config *configs.Config) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accessToken := readAccessToken(ctx)
 		if accessToken == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"status": "fail", "message": "You are not logged in"})
+			ctx.Next()
 			return
 		}
 
-		config, _ := configs.LoadConfig(".")
-		sub, err := utils.ValidateToken(accessToken, config.AccessTokenPublicKey)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"status": "fail", "message": err.Error()})
-			return
+		if authenticate(ctx, DB, config, accessToken) {
+			ctx.Next()
 		}
+	}
+}
 
-		var user models.User
-		result := configs.DB.Raw("SELECT * FROM users WHERE id = ?", fmt.Sprint(sub)).Scan(&user)
-		if result.Error != nil {
-			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"status": "fail", "message": "the user belonging to this token no logger exists"})
-			return
-		}
+func readAccessToken(ctx *gin.Context) string {
+	authorizationHeader := ctx.Request.Header.Get("Authorization")
+	fields := strings.Fields(authorizationHeader)
+
+	if len(fields) > 1 && fields[0] == "Token" {
+		return fields[1]
+	}
+
+	cookie, err := ctx.Cookie("access_token")
+	if err != nil {
+		return ""
+	}
+	return cookie
+}
 
-		ctx.Set("currentUser", user)
-		ctx.Set("token", accessToken)
-		ctx.Next()
+// authenticate sets currentUser and token for accessToken, or aborts the
+// request.
+func authenticate(ctx *gin.Context, DB *gorm.DB, config *configs.Config, accessToken string) bool {
+	sub, err := utils.ValidateToken(accessToken, config.AccessTokenPublicKey)
+	if err != nil {
+		apperrors.Abort(ctx, apperrors.Unauthorized(err.Error()))
+		return false
 	}
+
+	var user models.User
+	result := DB.WithContext(ctx.Request.Context()).Raw("SELECT * FROM users WHERE id = ?", fmt.Sprint(sub)).Scan(&user)
+	if result.Error != nil {
+		apperrors.Abort(ctx, apperrors.Internal(result.Error))
+		return false
+	} else if user.ID == 0 {
+		apperrors.Abort(ctx, apperrors.Forbidden("the user belonging to this token no longer exists"))
+		return false
+	} else if user.Status != models.UserStatusActive {
+		apperrors.Abort(ctx, apperrors.Forbidden("this account is not active"))
+		return false
+	}
+
+	ctx.Set("currentUser", user)
+	ctx.Set("token", accessToken)
+	return true
 }
@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestOptionalUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// requests without a token never reach the database
+	config := &configs.Config{}
+	engine := gin.New()
+	engine.Use(RenderErrors())
+	engine.GET("/optional", OptionalUser(nil, config), func(ctx *gin.Context) {
+		_, signedIn := ctx.Get("currentUser")
+		ctx.JSON(http.StatusOK, gin.H{"signedIn": signedIn})
+	})
+	engine.GET("/required", DeserializeUser(nil, config), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		path          string
+		authorization string
+		status        int
+	}{
+		{"/optional", "", http.StatusOK},
+		{"/optional", "Token not-a-jwt", http.StatusUnauthorized},
+		{"/required", "", http.StatusUnauthorized},
+		{"/required", "Token", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		if w.Code != test.status {
+			t.Errorf("GET %s with %q = %d, want %d: %s", test.path, test.authorization, w.Code, test.status, w.Body)
+		}
+	}
+}
+
+func TestAuthenticateReportsDatabaseFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &configs.Config{}
+	config.AccessTokenPrivateKey = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	config.AccessTokenPublicKey = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+
+	token, err := utils.CreateToken(time.Minute, 1, config.AccessTokenPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a database that cannot be reached must not pass for a deleted user
+	DB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=db.invalid connect_timeout=1"}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := gin.New()
+	engine.Use(RenderErrors())
+	engine.GET("/required", DeserializeUser(DB, config), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		canceled bool
+		status   int
+	}{
+		{"unreachable", false, http.StatusInternalServerError},
+		{"canceled", true, apperrors.StatusClientClosedRequest},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/required", nil)
+		req.Header.Set("Authorization", "Token "+token)
+		if test.canceled {
+			reqCtx, cancel := context.WithCancel(req.Context())
+			cancel()
+			req = req.WithContext(reqCtx)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		if w.Code != test.status {
+			t.Errorf("%s: GET /required = %d, want %d: %s", test.name, w.Code, test.status, w.Body)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// bucket holds the requests a client can still make, refilled at the rate
+// limit up to the burst.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func (rl *rateLimiter) allow(client string, rate float64, burst int, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// buckets that filled up again are the same as new ones, drop them
+	if now.Sub(rl.lastSweep) > time.Minute {
+		for key, b := range rl.buckets {
+			if now.Sub(b.last).Seconds()*rate >= float64(burst) {
+				delete(rl.buckets, key)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	b, ok := rl.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		rl.buckets[client] = b
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RateLimit allows each client IP the requests per second of the live
+// settings, so a reloaded limit applies to the next request.
+func RateLimit(live *configs.Live) gin.HandlerFunc {
+	limiter := &rateLimiter{buckets: make(map[string]*bucket)}
+
+	return func(ctx *gin.Context) {
+		settings := live.Load()
+		if settings.RateLimit <= 0 {
+			ctx.Next()
+			return
+		}
+
+		if !limiter.allow(ctx.ClientIP(), settings.RateLimit, settings.RateLimitBurst, time.Now()) {
+			// a token comes back after 1/rate seconds
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(1/settings.RateLimit))))
+			apperrors.Abort(ctx, apperrors.TooManyRequests("too many requests, slow down"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package middlewares
+
+import (
+	"fmt"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// RecoverPanics turns a panicking handler into an internal error, logged
+// with its stack trace. It has to run inside RenderErrors for the error to
+// be rendered.
+func RecoverPanics() gin.HandlerFunc {
+	return gin.CustomRecovery(func(ctx *gin.Context, recovered any) {
+		apperrors.Abort(ctx, apperrors.Internal(fmt.Errorf("panic: %v", recovered)))
+	})
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoverPanicsRendersTheError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(RenderErrors(), RecoverPanics())
+	engine.GET("/panic", func(ctx *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	var body struct {
+		Errors struct {
+			Body []string `json:"body"`
+		} `json:"errors"`
+	}
+	data, _ := io.ReadAll(w.Body)
+	if err := json.Unmarshal(data, &body); err != nil || w.Code != http.StatusInternalServerError || len(body.Errors.Body) != 1 {
+		t.Errorf("GET /panic = %d, %s", w.Code, data)
+	}
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"log"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// RenderErrors writes the last error reported by a handler as
+// {"errors":{"body":[...]}}. Internal errors are logged, not returned.
+func RenderErrors() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		err := apperrors.From(ctx.Errors.Last().Err)
+		switch err.Kind {
+		case apperrors.KindInternal, apperrors.KindTimeout:
+			log.Printf("%s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err.Err)
+		case apperrors.KindCanceled:
+			// nobody is listening anymore, only the status shows up in the logs
+			ctx.AbortWithStatus(err.Status())
+			return
+		}
+
+		ctx.JSON(err.Status(), gin.H{"errors": gin.H{"body": err.Messages}})
+	}
+}
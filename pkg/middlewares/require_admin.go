@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin must be chained after DeserializeUser.
+func RequireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		currentUser := ctx.MustGet("currentUser").(models.User)
+
+		if currentUser.Role != models.UserRoleAdmin {
+			apperrors.Abort(ctx, apperrors.Forbidden("admin privileges are required"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFeature answers 404 while enabled reports the feature turned off in
+// the live settings.
+func RequireFeature(live *configs.Live, enabled func(*configs.Settings) bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !enabled(live.Load()) {
+			apperrors.Abort(ctx, apperrors.NotFound("this feature is turned off"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
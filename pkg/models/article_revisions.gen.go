@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+const TableNameArticleRevision = "article_revisions"
+
+// ArticleRevision mapped from table <article_revisions>
+type ArticleRevision struct {
+	ID          int32     `gorm:"column:id;type:integer;primaryKey;autoIncrement:true" json:"id"`
+	IDArticle   int32     `gorm:"column:id_article;type:integer;not null" json:"id_article"`
+	Revision    int32     `gorm:"column:revision;type:integer;not null" json:"revision"`
+	IDAuthor    *int32    `gorm:"column:id_author;type:integer" json:"id_author"`
+	Title       string    `gorm:"column:title;type:text;not null" json:"title"`
+	Description string    `gorm:"column:description;type:text" json:"description"`
+	Body        string    `gorm:"column:body;type:text;not null" json:"body"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+type ArticleRevisionQueryResult struct {
+	Revision    int32     `json:"revision"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	Username    *string   `json:"username"`
+}
+
+type ArticleRevisionResponse struct {
+	Revision    int32     `json:"revision"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Body        string    `json:"body,omitempty"`
+	Author      *string   `json:"author"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type ArticleRevisionDiff struct {
+	From        int32  `json:"from"`
+	To          int32  `json:"to"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Body        string `json:"body"`
+}
+
+// TableName ArticleRevision's table name
+func (*ArticleRevision) TableName() string {
+	return TableNameArticleRevision
+}
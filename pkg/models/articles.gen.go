@@ -6,29 +6,54 @@ import (
 
 const TableNameArticle = "articles"
 
+const (
+	ArticleStatusDraft       = "draft"
+	ArticleStatusScheduled   = "scheduled"
+	ArticleStatusPublished   = "published"
+	ArticleStatusUnpublished = "unpublished"
+)
+
+const (
+	ArticleVisibilityPublic    = "public"
+	ArticleVisibilityUnlisted  = "unlisted"
+	ArticleVisibilityFollowers = "followers"
+)
+
 // Article mapped from table <articles>
 type Article struct {
-	ID          int32     `gorm:"column:id;type:integer;primaryKey;autoIncrement:true" json:"id"`
-	IDAuthor    int32     `gorm:"column:id_author;type:integer;not null" json:"id_author"`
-	Slug        string    `gorm:"column:slug;type:text;not null" json:"slug"`
-	Title       string    `gorm:"column:title;type:text;not null" json:"title"`
-	Description string    `gorm:"column:description;type:text" json:"description"`
-	Body        string    `gorm:"column:body;type:text;not null" json:"body"`
-	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID             int32      `gorm:"column:id;type:integer;primaryKey;autoIncrement:true" json:"id"`
+	IDAuthor       int32      `gorm:"column:id_author;type:integer;not null" json:"id_author"`
+	Slug           string     `gorm:"column:slug;type:text;not null" json:"slug"`
+	Title          string     `gorm:"column:title;type:text;not null" json:"title"`
+	Description    string     `gorm:"column:description;type:text" json:"description"`
+	Body           string     `gorm:"column:body;type:text;not null" json:"body"`
+	Status         string     `gorm:"column:status;type:text;not null;default:published" json:"status"`
+	PublishAt      *time.Time `gorm:"column:publish_at;type:timestamp with time zone" json:"publish_at"`
+	Visibility     string     `gorm:"column:visibility;type:text;not null;default:public" json:"visibility"`
+	FavoritesCount int32      `gorm:"column:favorites_count;type:integer;not null;default:0" json:"favorites_count"`
+	CommentsCount  int32      `gorm:"column:comments_count;type:integer;not null;default:0" json:"comments_count"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt      *time.Time `gorm:"column:deleted_at;type:timestamp with time zone" json:"deleted_at"`
 }
 
 type ArticleRequest struct {
-	Title       string   `json:"title" binding:"required"`
-	Description string   `json:"description" binding:"required"`
-	Body        string   `json:"body" binding:"required"`
-	TagList     []string `json:"tagList,omitempty"`
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description" binding:"required"`
+	Body        string     `json:"body" binding:"required"`
+	TagList     []string   `json:"tagList,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	PublishAt   *time.Time `json:"publishAt,omitempty"`
+	Visibility  string     `json:"visibility,omitempty"`
 }
 
 type ArticleUpdate struct {
-	Title       string `json:"title,omitempty"`
-	Description string `json:"description,omitempty"`
-	Body        string `json:"body,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Body        string     `json:"body,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	PublishAt   *time.Time `json:"publishAt,omitempty"`
+	Visibility  string     `json:"visibility,omitempty"`
 }
 
 type ArticleUpdateRequest struct {
@@ -40,6 +65,7 @@ type ArticleCreateRequest struct {
 }
 
 type ArticleCommon struct {
+	ID             int32        `json:"-"`
 	Slug           string       `json:"slug"`
 	Title          string       `json:"title"`
 	Description    string       `json:"description"`
@@ -47,9 +73,33 @@ type ArticleCommon struct {
 	TagList        []string     `json:"tagList"`
 	CreatedAt      time.Time    `json:"createdAt"`
 	UpdatedAt      time.Time    `json:"updatedAt"`
+	Status         string       `json:"status"`
+	PublishAt      *time.Time   `json:"publishAt"`
+	Visibility     string       `json:"visibility"`
 	Favorited      bool         `json:"favorited"`
 	FavoritesCount int32        `json:"favoritesCount"`
+	CommentsCount  int32        `json:"commentsCount"`
 	Author         *UserProfile `json:"author"`
+	BodyHTML       *string      `json:"bodyHtml,omitempty"`
+	TOC            []TOCEntry   `json:"toc,omitempty"`
+	WordCount      *int         `json:"wordCount,omitempty"`
+	ReadingTime    *int         `json:"readingTime,omitempty"`
+	Rank           *float64     `json:"rank,omitempty"`
+	Highlight      *Highlight   `json:"highlight,omitempty"`
+}
+
+// Highlight holds HTML excerpts of an article with the search matches
+// wrapped in <mark>.
+type Highlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Body        string `json:"body"`
+}
+
+type TOCEntry struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
 }
 
 type ArticleResponse struct {
@@ -57,24 +107,44 @@ type ArticleResponse struct {
 }
 
 type ArticleQueryResult struct {
-	ID              int32     `json:"id"`
-	Slug            string    `json:"slug"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	Body            string    `json:"body"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	IDAuthor        int32     `json:"id_author"`
-	Username        string    `json:"username"`
-	Bio             *string   `json:"bio"`
-	Image           *string   `json:"image"`
-	Following       bool      `json:"following"`
-	TagID           int32     `json:"tag_id"`
-	TagName         string    `json:"tag_name"`
-	LikedBy         int32     `json:"liked_by"`
-	LikedByUsername string    `json:"liked_by_username"`
-	Favorited       bool      `json:"favorited"`
-	FavoritesCount  int32     `json:"favorites_count"`
+	ID             int32      `json:"id"`
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Body           string     `json:"body"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	IDAuthor       int32      `json:"id_author"`
+	Status         string     `json:"status"`
+	PublishAt      *time.Time `json:"publish_at"`
+	Visibility     string     `json:"visibility"`
+	Username       string     `json:"username"`
+	Bio            *string    `json:"bio"`
+	Image          *string    `json:"image"`
+	Following      bool       `json:"following"`
+	Favorited      bool       `json:"favorited"`
+	FavoritesCount int32      `json:"favorites_count"`
+	CommentsCount  int32      `json:"comments_count"`
+	TagList        StringList `json:"tag_list"`
+}
+
+// ArticlePageRow is an article of a listing page together with the value
+// it is sorted by and the size of the whole listing, when it was counted.
+type ArticlePageRow struct {
+	ArticleQueryResult
+	SortTime   time.Time `json:"sort_time"`
+	SortNumber *float64  `json:"sort_number"`
+	Total      *int64    `json:"total"`
+}
+
+// ArticleSearchResult is a search match as read from the database, with
+// the text to highlight.
+type ArticleSearchResult struct {
+	ID          int32   `json:"id"`
+	Rank        float64 `json:"rank"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Body        string  `json:"body"`
 }
 
 type FavoritesCountQueryResult struct {
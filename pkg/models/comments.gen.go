@@ -8,12 +8,13 @@ const TableNameComment = "comments"
 
 // Comment mapped from table <comments>
 type Comment struct {
-	ID        int32     `gorm:"column:id;type:integer;primaryKey;autoIncrement:true" json:"id"`
-	IDAuthor  int32     `gorm:"column:id_author;type:integer;not null" json:"id_author"`
-	IDArticle int32     `gorm:"column:id_article;type:integer;not null" json:"id_article"`
-	Body      string    `gorm:"column:body;type:text;not null" json:"body"`
-	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID        int32      `gorm:"column:id;type:integer;primaryKey;autoIncrement:true" json:"id"`
+	IDAuthor  int32      `gorm:"column:id_author;type:integer" json:"id_author"`
+	IDArticle int32      `gorm:"column:id_article;type:integer;not null" json:"id_article"`
+	Body      string     `gorm:"column:body;type:text;not null" json:"body"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"column:deleted_at;type:timestamp with time zone" json:"deleted_at"`
 }
 
 type CommentRequest struct {
@@ -25,16 +26,17 @@ type CommentCreateRequest struct {
 }
 
 type CommentQueryResult struct {
-	ID        int32     `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	IDAuthor  int32     `json:"id_author"`
-	IDArticle int32     `json:"id_article"`
-	Username  string    `json:"username"`
-	Bio       *string   `json:"bio"`
-	Image     *string   `json:"image"`
-	Following bool      `json:"following"`
+	ID        int32      `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	IDAuthor  int32      `json:"id_author"`
+	IDArticle int32      `json:"id_article"`
+	Username  string     `json:"username"`
+	Bio       *string    `json:"bio"`
+	Image     *string    `json:"image"`
+	Following bool       `json:"following"`
+	DeletedAt *time.Time `json:"deleted_at"`
 }
 
 type CommentResponse struct {
@@ -45,6 +47,9 @@ type CommentResponse struct {
 	Author    *UserProfile `json:"author"`
 }
 
+// CommentDeletedBody replaces the body of comments that are in the trash.
+const CommentDeletedBody = "[deleted]"
+
 type CommentResponseData struct {
 	Comment *CommentResponse `json:"comment"`
 }
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+type ExportProfile struct {
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Bio       *string   `json:"bio"`
+	Image     *string   `json:"image"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ExportArticle struct {
+	ID             int32     `json:"-"`
+	Slug           string    `json:"slug"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Body           string    `json:"body"`
+	TagList        []string  `json:"tagList" gorm:"-"`
+	Status         string    `json:"status"`
+	Visibility     string    `json:"visibility"`
+	FavoritesCount int32     `json:"favoritesCount"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+type ExportArticleTag struct {
+	IDArticle int32  `json:"id_article"`
+	Name      string `json:"name"`
+}
+
+type ExportComment struct {
+	ID          int32     `json:"id"`
+	ArticleSlug string    `json:"articleSlug"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type ExportRevision struct {
+	ArticleSlug string    `json:"articleSlug"`
+	Revision    int32     `json:"revision"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type ExportUpload struct {
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+const TableNameInviteCode = "invite_codes"
+
+// InviteCode mapped from table <invite_codes>
+type InviteCode struct {
+	ID        int32      `gorm:"column:id;type:integer;primaryKey;autoIncrement:true" json:"id"`
+	Code      string     `gorm:"column:code;type:text;not null;unique" json:"code"`
+	MaxUses   int32      `gorm:"column:max_uses;type:integer;not null;default:1" json:"max_uses"`
+	Uses      int32      `gorm:"column:uses;type:integer;not null;default:0" json:"uses"`
+	CreatedBy *int32     `gorm:"column:created_by;type:integer" json:"created_by"`
+	ExpiresAt *time.Time `gorm:"column:expires_at;type:timestamp with time zone" json:"expires_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+type InviteCodeRequest struct {
+	MaxUses   *int32     `json:"maxUses"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type InviteCodeCreateRequest struct {
+	Invite InviteCodeRequest `json:"invite"`
+}
+
+type InviteCodeResponse struct {
+	Code      string     `json:"code"`
+	MaxUses   int32      `json:"maxUses"`
+	Uses      int32      `json:"uses"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type InviteCodeResponseData struct {
+	Invite *InviteCodeResponse `json:"invite"`
+}
+
+// TableName InviteCode's table name
+func (*InviteCode) TableName() string {
+	return TableNameInviteCode
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// StringList reads a JSON array of strings, as built by json_agg, from a
+// query result.
+type StringList []string
+
+func (sl *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*sl = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, sl)
+	case string:
+		return json.Unmarshal([]byte(v), sl)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+}
+
+func (sl StringList) Value() (driver.Value, error) {
+	if sl == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(sl))
+	return string(data), err
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+type TrashArticle struct {
+	Slug      string    `json:"slug"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt" gorm:"-"`
+}
+
+type TrashComment struct {
+	ID          int32     `json:"id"`
+	ArticleSlug string    `json:"articleSlug"`
+	Body        string    `json:"body"`
+	DeletedAt   time.Time `json:"deletedAt"`
+	PurgeAt     time.Time `json:"purgeAt" gorm:"-"`
+}
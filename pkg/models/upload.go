@@ -0,0 +1,14 @@
+package models
+
+type Upload struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	ContentType  string `json:"contentType"`
+	Size         int    `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+type UploadResponse struct {
+	Upload *Upload `json:"upload"`
+}
@@ -4,12 +4,17 @@
 
 package models
 
+import (
+	"time"
+)
+
 const TableNameUserFollow = "user_follow"
 
 // UserFollow mapped from table <user_follow>
 type UserFollow struct {
-	IDUserA int32 `gorm:"column:id_user_a;type:integer;primaryKey" json:"id_user_a"`
-	IDUserB int32 `gorm:"column:id_user_b;type:integer;primaryKey" json:"id_user_b"`
+	IDUserA   int32     `gorm:"column:id_user_a;type:integer;primaryKey" json:"id_user_a"`
+	IDUserB   int32     `gorm:"column:id_user_b;type:integer;primaryKey" json:"id_user_b"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 }
 
 // TableName UserFollow's table name
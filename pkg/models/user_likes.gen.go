@@ -4,12 +4,17 @@
 
 package models
 
+import (
+	"time"
+)
+
 const TableNameUserLike = "user_likes"
 
 // UserLike mapped from table <user_likes>
 type UserLike struct {
-	IDUser    int32 `gorm:"column:id_user;type:integer;primaryKey" json:"id_user"`
-	IDArticle int32 `gorm:"column:id_article;type:integer;primaryKey" json:"id_article"`
+	IDUser    int32     `gorm:"column:id_user;type:integer;primaryKey" json:"id_user"`
+	IDArticle int32     `gorm:"column:id_article;type:integer;primaryKey" json:"id_article"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 }
 
 // TableName UserLike's table name
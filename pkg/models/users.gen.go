@@ -6,6 +6,16 @@ import (
 
 const TableNameUser = "users"
 
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+
+	UserStatusActive   = "active"
+	UserStatusPending  = "pending"
+	UserStatusDeleted  = "deleted"
+	UserStatusDisabled = "disabled"
+)
+
 // User mapped from table <users>
 type User struct {
 	ID        int32     `gorm:"column:id;type:integer;primaryKey;autoIncrement:true" json:"id"`
@@ -14,14 +24,17 @@ type User struct {
 	Password  string    `gorm:"column:password;type:text;not null" json:"password"`
 	Bio       *string   `gorm:"column:bio;type:text" json:"bio"`
 	Image     *string   `gorm:"column:image;type:text" json:"image"`
+	Role      string    `gorm:"column:role;type:text;not null;default:user" json:"role"`
+	Status    string    `gorm:"column:status;type:text;not null;default:active" json:"status"`
 	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
 type UserRegister struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Username string `json:"username" binding:"required"`
+	Email      string `json:"email" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	Username   string `json:"username" binding:"required"`
+	InviteCode string `json:"inviteCode,omitempty"`
 }
 
 type UserRegisterRequest struct {
@@ -49,11 +62,36 @@ type UserUpdateRequest struct {
 	User UserUpdate `json:"user" binding:"required"`
 }
 
+type UserReauthenticate struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type UserDeleteRequest struct {
+	User UserReauthenticate `json:"user" binding:"required"`
+}
+
 type UserProfile struct {
-	Username  string  `json:"username"`
-	Bio       *string `json:"bio"`
-	Image     *string `json:"image"`
-	Following bool    `json:"following"`
+	Username       string  `json:"username"`
+	Bio            *string `json:"bio"`
+	Image          *string `json:"image"`
+	Following      bool    `json:"following"`
+	FollowersCount *int32  `json:"followersCount,omitempty"`
+	FollowingCount *int32  `json:"followingCount,omitempty"`
+	ArticlesCount  *int32  `json:"articlesCount,omitempty"`
+}
+
+type UserSuggestion struct {
+	UserProfile
+	SharedTags    int32 `json:"sharedTags"`
+	MutualFollows int32 `json:"mutualFollows"`
+}
+
+// UserFollowProfile is a profile listed as follower or followed, with the
+// keys the list is ordered by.
+type UserFollowProfile struct {
+	UserProfile
+	ID        int32     `json:"-"`
+	CreatedAt time.Time `json:"-"`
 }
 
 type UserProfileResponse struct {
@@ -72,6 +110,12 @@ type UserResponse struct {
 	User *UserCommon `json:"user"`
 }
 
+type PendingUser struct {
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 // TableName User's table name
 func (*User) TableName() string {
 	return TableNameUser
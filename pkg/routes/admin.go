@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/controllers"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminRouteController struct {
+	adminController controllers.AdminController
+	requireUser     gin.HandlerFunc
+}
+
+func NewAdminRouteController(adminController controllers.AdminController, requireUser gin.HandlerFunc) AdminRouteController {
+	return AdminRouteController{adminController, requireUser}
+}
+
+func (adrc *AdminRouteController) AdminRoute(rg *gin.RouterGroup) {
+	router := rg.Group("admin", adrc.requireUser, middlewares.RequireAdmin())
+	router.POST("/invites", adrc.adminController.CreateInviteCode)
+	router.GET("/invites", adrc.adminController.GetInviteCodes)
+	router.DELETE("/invites/:code", adrc.adminController.DeleteInviteCode)
+	router.GET("/users/pending", adrc.adminController.GetPendingUsers)
+	router.POST("/users/:username/approve", adrc.adminController.ApproveUser)
+	router.POST("/users/:username/reject", adrc.adminController.RejectUser)
+}
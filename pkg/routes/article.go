@@ -2,30 +2,38 @@ package routes
 
 import (
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/controllers"
-	"github.com/RayhanAnandhias/realworld-project-golang/pkg/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
 type ArticleRouteController struct {
 	ArticleController controllers.ArticleController
 	CommentController controllers.CommentController
+	RequireSearch     gin.HandlerFunc
+	RequireUser       gin.HandlerFunc
+	OptionalUser      gin.HandlerFunc
 }
 
-func NewArticleRouteController(ArticleController controllers.ArticleController, CommentController controllers.CommentController) ArticleRouteController {
-	return ArticleRouteController{ArticleController, CommentController}
+func NewArticleRouteController(ArticleController controllers.ArticleController, CommentController controllers.CommentController, RequireSearch gin.HandlerFunc, RequireUser gin.HandlerFunc, OptionalUser gin.HandlerFunc) ArticleRouteController {
+	return ArticleRouteController{ArticleController, CommentController, RequireSearch, RequireUser, OptionalUser}
 }
 
 func (arc *ArticleRouteController) ArticleRoute(rg *gin.RouterGroup) {
 	router := rg.Group("articles")
-	router.POST("/", middlewares.DeserializeUser(), arc.ArticleController.CreateArticle)
+	router.POST("/", arc.RequireUser, arc.ArticleController.CreateArticle)
 	router.GET("/", arc.ArticleController.GetAllArticles)
-	router.GET("/feed", middlewares.DeserializeUser(), arc.ArticleController.GetFeedArticles)
-	router.GET("/:slug", middlewares.DeserializeUser(), arc.ArticleController.GetArticleBySlug)
-	router.PUT("/:slug", middlewares.DeserializeUser(), arc.ArticleController.UpdateArticle)
-	router.POST("/:slug/favorite", middlewares.DeserializeUser(), arc.ArticleController.FavoriteArticle)
-	router.DELETE("/:slug/favorite", middlewares.DeserializeUser(), arc.ArticleController.UnfavoriteArticle)
-	router.POST("/:slug/comments", middlewares.DeserializeUser(), arc.CommentController.CreateComment)
-	router.GET("/:slug/comments", middlewares.DeserializeUser(), arc.CommentController.GetCommentsForArticle)
-	router.DELETE("/:slug/comments/:commentId", middlewares.DeserializeUser(), arc.CommentController.DeleteCommentForArticle)
-	router.DELETE("/:slug", middlewares.DeserializeUser(), arc.ArticleController.DeleteArticle)
+	router.GET("/search", arc.RequireSearch, arc.OptionalUser, arc.ArticleController.SearchArticles)
+	router.GET("/feed", arc.RequireUser, arc.ArticleController.GetFeedArticles)
+	router.GET("/drafts", arc.RequireUser, arc.ArticleController.GetDraftArticles)
+	router.GET("/:slug", arc.RequireUser, arc.ArticleController.GetArticleBySlug)
+	router.PUT("/:slug", arc.RequireUser, arc.ArticleController.UpdateArticle)
+	router.GET("/:slug/revisions", arc.RequireUser, arc.ArticleController.GetArticleRevisions)
+	router.GET("/:slug/revisions/diff", arc.RequireUser, arc.ArticleController.DiffArticleRevisions)
+	router.GET("/:slug/revisions/:revision", arc.RequireUser, arc.ArticleController.GetArticleRevision)
+	router.POST("/:slug/revisions/:revision/restore", arc.RequireUser, arc.ArticleController.RestoreArticleRevision)
+	router.POST("/:slug/favorite", arc.RequireUser, arc.ArticleController.FavoriteArticle)
+	router.DELETE("/:slug/favorite", arc.RequireUser, arc.ArticleController.UnfavoriteArticle)
+	router.POST("/:slug/comments", arc.RequireUser, arc.CommentController.CreateComment)
+	router.GET("/:slug/comments", arc.RequireUser, arc.CommentController.GetCommentsForArticle)
+	router.DELETE("/:slug/comments/:commentId", arc.RequireUser, arc.CommentController.DeleteCommentForArticle)
+	router.DELETE("/:slug", arc.RequireUser, arc.ArticleController.DeleteArticle)
 }
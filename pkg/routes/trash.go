@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+type TrashRouteController struct {
+	trashController controllers.TrashController
+	requireUser     gin.HandlerFunc
+}
+
+func NewTrashRouteController(trashController controllers.TrashController, requireUser gin.HandlerFunc) TrashRouteController {
+	return TrashRouteController{trashController, requireUser}
+}
+
+func (trc *TrashRouteController) TrashRoute(rg *gin.RouterGroup) {
+	router := rg.Group("user/trash", trc.requireUser)
+	router.GET("/", trc.trashController.GetTrash)
+	router.POST("/articles/:slug/restore", trc.trashController.RestoreArticle)
+	router.POST("/comments/:commentId/restore", trc.trashController.RestoreComment)
+}
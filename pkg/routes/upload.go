@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+type UploadRouteController struct {
+	uploadController controllers.UploadController
+	requireUser      gin.HandlerFunc
+}
+
+func NewUploadRouteController(uploadController controllers.UploadController, requireUser gin.HandlerFunc) UploadRouteController {
+	return UploadRouteController{uploadController, requireUser}
+}
+
+func (uprc *UploadRouteController) UploadRoute(rg *gin.RouterGroup) {
+	rg.POST("/user/image", uprc.requireUser, uprc.uploadController.UploadAvatar)
+
+	router := rg.Group("uploads")
+	router.POST("/images", uprc.requireUser, uprc.uploadController.UploadArticleImage)
+}
+
+func (uprc *UploadRouteController) MediaRoute(rg *gin.RouterGroup) {
+	router := rg.Group("media")
+	router.GET("/*key", uprc.uploadController.ServeMedia)
+	router.HEAD("/*key", uprc.uploadController.ServeMedia)
+}
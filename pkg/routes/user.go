@@ -2,16 +2,16 @@ package routes
 
 import (
 	"github.com/RayhanAnandhias/realworld-project-golang/pkg/controllers"
-	"github.com/RayhanAnandhias/realworld-project-golang/pkg/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
 type UserRouteController struct {
 	userController controllers.UserController
+	requireUser    gin.HandlerFunc
 }
 
-func NewUserRouteController(userController controllers.UserController) UserRouteController {
-	return UserRouteController{userController}
+func NewUserRouteController(userController controllers.UserController, requireUser gin.HandlerFunc) UserRouteController {
+	return UserRouteController{userController, requireUser}
 }
 
 func (urc *UserRouteController) UserRoute(rg *gin.RouterGroup) {
@@ -24,13 +24,19 @@ func (urc *UserRouteController) UserRoute(rg *gin.RouterGroup) {
 
 func (urc *UserRouteController) SingleUserRoute(rg *gin.RouterGroup) {
 	router := rg.Group("user")
-	router.GET("/", middlewares.DeserializeUser(), urc.userController.GetCurrentUser)
-	router.PUT("/", middlewares.DeserializeUser(), urc.userController.UpdateCurrentUser)
+	router.GET("/", urc.requireUser, urc.userController.GetCurrentUser)
+	router.PUT("/", urc.requireUser, urc.userController.UpdateCurrentUser)
+	router.DELETE("/", urc.requireUser, urc.userController.DeleteCurrentUser)
+	router.GET("/export", urc.requireUser, urc.userController.ExportCurrentUser)
 }
 
 func (urc *UserRouteController) ProfileRoute(rg *gin.RouterGroup) {
 	router := rg.Group("profiles")
-	router.GET("/:profileUsername", middlewares.DeserializeUser(), urc.userController.GetProfile)
-	router.POST("/:profileUsername/follow", middlewares.DeserializeUser(), urc.userController.FollowUser)
-	router.DELETE("/:profileUsername/follow", middlewares.DeserializeUser(), urc.userController.UnfollowUser)
+	router.GET("/", urc.requireUser, urc.userController.SearchProfiles)
+	router.GET("/suggested", urc.requireUser, urc.userController.GetSuggestedAuthors)
+	router.GET("/:profileUsername", urc.requireUser, urc.userController.GetProfile)
+	router.GET("/:profileUsername/followers", urc.requireUser, urc.userController.GetFollowers)
+	router.GET("/:profileUsername/following", urc.requireUser, urc.userController.GetFollowing)
+	router.POST("/:profileUsername/follow", urc.requireUser, urc.userController.FollowUser)
+	router.DELETE("/:profileUsername/follow", urc.requireUser, urc.userController.UnfollowUser)
 }
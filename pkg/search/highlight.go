@@ -0,0 +1,23 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Highlights start with StartSel and end with StopSel until Mark turns them
+// into HTML. Private use characters can't clash with article text.
+const (
+	StartSel = "\uE000"
+	StopSel  = "\uE001"
+)
+
+// HeadlineOptions are the ts_headline options producing what Mark expects.
+const HeadlineOptions = "StartSel=" + StartSel + ", StopSel=" + StopSel + ", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// Mark escapes s and turns the highlight selectors into <mark> elements.
+func Mark(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, StartSel, "<mark>")
+	return strings.ReplaceAll(s, StopSel, "</mark>")
+}
@@ -0,0 +1,94 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Term is one part of a search: a single word, possibly a prefix, or a
+// phrase whose words have to appear next to each other.
+type Term struct {
+	Words  []string
+	Prefix bool
+}
+
+// Query is a parsed search. Every term has to match.
+type Query struct {
+	Terms []Term
+}
+
+// Parse reads a search typed by a user. Quoted text is a phrase, a word
+// ending in * is a prefix, anything that isn't a letter or digit separates
+// words and is otherwise ignored.
+func Parse(s string) Query {
+	var query Query
+
+	rest := s
+	for len(rest) != 0 {
+		start := strings.IndexByte(rest, '"')
+		if start < 0 {
+			query.Terms = append(query.Terms, parseWords(rest)...)
+			break
+		}
+
+		query.Terms = append(query.Terms, parseWords(rest[:start])...)
+		rest = rest[start+1:]
+
+		end := strings.IndexByte(rest, '"')
+		if end < 0 {
+			end = len(rest)
+		}
+
+		if words := splitWords(rest[:end]); len(words) != 0 {
+			query.Terms = append(query.Terms, Term{Words: words})
+		}
+
+		if end == len(rest) {
+			break
+		}
+		rest = rest[end+1:]
+	}
+
+	return query
+}
+
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0
+}
+
+// TSQuery renders the query for Postgres' to_tsquery.
+func (q Query) TSQuery() string {
+	parts := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		part := strings.Join(term.Words, " <-> ")
+		if term.Prefix {
+			part += ":*"
+		}
+		if len(term.Words) > 1 {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " & ")
+}
+
+func parseWords(s string) []Term {
+	var terms []Term
+	for _, field := range strings.Fields(s) {
+		prefix := strings.HasSuffix(field, "*")
+		words := splitWords(field)
+		if len(words) == 0 {
+			continue
+		}
+
+		// a token like "e-mail*" is a phrase whose last word is a prefix
+		terms = append(terms, Term{Words: words, Prefix: prefix})
+	}
+	return terms
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,294 @@
+// Package seed fills a database with generated demo data. The data only
+// depends on Options, so the same options always load the same dataset.
+package seed
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/fixtures"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const batchSize = 500
+
+type Options struct {
+	Seed               int64
+	Users              int
+	ArticlesPerUser    int
+	FollowsPerUser     int
+	LikesPerUser       int
+	CommentsPerArticle int
+	// Password every user can log in with.
+	Password string
+	// Now is the end of the 90 days the data is spread over.
+	Now time.Time
+}
+
+// DefaultOptions end the data on a fixed date, so the dataset doesn't change
+// from one day to the next.
+func DefaultOptions() Options {
+	return Options{
+		Seed:               1,
+		Users:              20,
+		ArticlesPerUser:    3,
+		FollowsPerUser:     5,
+		LikesPerUser:       10,
+		CommentsPerArticle: 3,
+		Password:           "password",
+		Now:                time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// Dataset is generated data waiting to be loaded. IDs are positions in the
+// slices plus one, they are replaced by the database's IDs when loaded.
+type Dataset struct {
+	Users       []models.User
+	Follows     []models.UserFollow
+	Tags        []models.Tag
+	Articles    []models.Article
+	ArticleTags []models.ArticleTag
+	Revisions   []models.ArticleRevision
+	Likes       []models.UserLike
+	Comments    []models.Comment
+}
+
+// Generate builds the dataset for options. Users store options.Password as
+// is, Load hashes it.
+func Generate(options Options) Dataset {
+	f := fixtures.New(options.Seed, options.Now)
+	r := f.Rand()
+	var data Dataset
+
+	for i := 0; i < options.Users; i++ {
+		user := f.User(options.Password)
+		user.ID = int32(i + 1)
+		data.Users = append(data.Users, user)
+	}
+	if len(data.Users) == 0 {
+		return data
+	}
+
+	// a few authors are followed by everybody, most by hardly anyone
+	popularUsers := zipf(r, len(data.Users))
+	for _, follower := range data.Users {
+		followed := make(map[int32]bool)
+		for attempt := 0; len(followed) < options.FollowsPerUser && attempt < 4*options.FollowsPerUser; attempt++ {
+			user := data.Users[popularUsers()]
+			if user.ID == follower.ID || followed[user.ID] {
+				continue
+			}
+			followed[user.ID] = true
+			data.Follows = append(data.Follows, f.UserFollow(follower, user))
+		}
+	}
+
+	tags := make(map[string]models.Tag)
+	for _, author := range data.Users {
+		for i := 0; i < options.ArticlesPerUser; i++ {
+			var article models.Article
+			switch n := r.Intn(20); {
+			case n == 0:
+				article = f.Article(author, fixtures.Draft)
+			case n == 1:
+				article = f.Article(author, fixtures.Visibility(models.ArticleVisibilityUnlisted))
+			case n == 2:
+				article = f.Article(author, fixtures.Visibility(models.ArticleVisibilityFollowers))
+			default:
+				article = f.Article(author)
+			}
+			article.ID = int32(len(data.Articles) + 1)
+			data.Articles = append(data.Articles, article)
+			data.Revisions = append(data.Revisions, f.Revision(article, 1))
+
+			tagged := make(map[string]bool)
+			for t := 1 + r.Intn(3); t > 0; t-- {
+				name := f.TagName()
+				if tagged[name] {
+					continue
+				}
+				tagged[name] = true
+
+				tag, ok := tags[name]
+				if !ok {
+					tag = f.Tag(name)
+					tag.ID = int32(len(data.Tags) + 1)
+					tags[name] = tag
+					data.Tags = append(data.Tags, tag)
+				}
+				data.ArticleTags = append(data.ArticleTags, f.ArticleTag(article, tag))
+			}
+		}
+	}
+
+	// only published articles get likes and comments, again skewed so a few
+	// of them make up the top of the feed
+	var published []int
+	for i, article := range data.Articles {
+		if article.Status == models.ArticleStatusPublished {
+			published = append(published, i)
+		}
+	}
+	if len(published) == 0 {
+		return data
+	}
+	r.Shuffle(len(published), func(i, j int) { published[i], published[j] = published[j], published[i] })
+	popularArticles := zipf(r, len(published))
+
+	for _, user := range data.Users {
+		liked := make(map[int]bool)
+		for attempt := 0; len(liked) < options.LikesPerUser && attempt < 4*options.LikesPerUser; attempt++ {
+			i := published[popularArticles()]
+			if liked[i] {
+				continue
+			}
+			liked[i] = true
+			data.Articles[i].FavoritesCount++
+			data.Likes = append(data.Likes, f.UserLike(user, data.Articles[i]))
+		}
+	}
+
+	for c := options.CommentsPerArticle * len(data.Articles); c > 0; c-- {
+		i := published[popularArticles()]
+		author := data.Users[r.Intn(len(data.Users))]
+		data.Articles[i].CommentsCount++
+		data.Comments = append(data.Comments, f.Comment(author, data.Articles[i]))
+	}
+
+	// rows go in oldest first, so IDs grow with time like they would in use
+	sort.SliceStable(data.Comments, func(i, j int) bool {
+		return data.Comments[i].CreatedAt.Before(data.Comments[j].CreatedAt)
+	})
+
+	return data
+}
+
+// zipf returns a picker of indices below n where low indices come up far
+// more often than high ones.
+func zipf(r *rand.Rand, n int) func() int {
+	z := rand.NewZipf(r, 1.2, 2, uint64(n-1))
+	return func() int { return int(z.Uint64()) }
+}
+
+// ErrAlreadySeeded is returned by Load when the dataset's first user exists.
+var ErrAlreadySeeded = errors.New("the database already holds this dataset")
+
+// Load inserts data in a single transaction and sets the database's IDs on
+// the rows.
+func Load(DB *gorm.DB, data *Dataset) error {
+	if len(data.Users) == 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := DB.Raw(`SELECT EXISTS (SELECT 1 FROM users WHERE username = ?)`, data.Users[0].Username).Scan(&exists).Error; err != nil {
+		return err
+	} else if exists {
+		return ErrAlreadySeeded
+	}
+
+	// every user shares the password, one hash is enough
+	hashedPassword, err := utils.HashPassword(data.Users[0].Password)
+	if err != nil {
+		return err
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		userIDs := make(map[int32]int32, len(data.Users))
+		for i := range data.Users {
+			data.Users[i].Password = hashedPassword
+		}
+		if err := insert(tx, data.Users, func(i int) *int32 { return &data.Users[i].ID }, userIDs); err != nil {
+			return err
+		}
+
+		// tags are shared with whatever is already there
+		tagIDs := make(map[int32]int32, len(data.Tags))
+		for i, tag := range data.Tags {
+			queryTag := `
+				INSERT INTO tags (name, created_at, updated_at) VALUES (?, ?, ?)
+				ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+				RETURNING id`
+			if err := tx.Raw(queryTag, tag.Name, tag.CreatedAt, tag.UpdatedAt).Scan(&data.Tags[i].ID).Error; err != nil {
+				return err
+			}
+			tagIDs[tag.ID] = data.Tags[i].ID
+		}
+
+		articleIDs := make(map[int32]int32, len(data.Articles))
+		for i := range data.Articles {
+			data.Articles[i].IDAuthor = userIDs[data.Articles[i].IDAuthor]
+		}
+		if err := insert(tx, data.Articles, func(i int) *int32 { return &data.Articles[i].ID }, articleIDs); err != nil {
+			return err
+		}
+
+		for i := range data.Follows {
+			data.Follows[i].IDUserA = userIDs[data.Follows[i].IDUserA]
+			data.Follows[i].IDUserB = userIDs[data.Follows[i].IDUserB]
+		}
+		for i := range data.ArticleTags {
+			data.ArticleTags[i].IDArticle = articleIDs[data.ArticleTags[i].IDArticle]
+			data.ArticleTags[i].IDTag = tagIDs[data.ArticleTags[i].IDTag]
+		}
+		for i := range data.Revisions {
+			data.Revisions[i].IDArticle = articleIDs[data.Revisions[i].IDArticle]
+			author := userIDs[*data.Revisions[i].IDAuthor]
+			data.Revisions[i].IDAuthor = &author
+		}
+		for i := range data.Likes {
+			data.Likes[i].IDUser = userIDs[data.Likes[i].IDUser]
+			data.Likes[i].IDArticle = articleIDs[data.Likes[i].IDArticle]
+		}
+		for i := range data.Comments {
+			data.Comments[i].IDAuthor = userIDs[data.Comments[i].IDAuthor]
+			data.Comments[i].IDArticle = articleIDs[data.Comments[i].IDArticle]
+		}
+
+		if err := insert(tx, data.Follows, nil, nil); err != nil {
+			return err
+		}
+		if err := insert(tx, data.ArticleTags, nil, nil); err != nil {
+			return err
+		}
+		if err := insert(tx, data.Revisions, func(i int) *int32 { return &data.Revisions[i].ID }, nil); err != nil {
+			return err
+		}
+		if err := insert(tx, data.Likes, nil, nil); err != nil {
+			return err
+		}
+		return insert(tx, data.Comments, func(i int) *int32 { return &data.Comments[i].ID }, nil)
+	})
+}
+
+// insert creates rows in batches. When ids is given, the generated ID each
+// row had before the insert is mapped to the one the database gave it.
+func insert[T any](tx *gorm.DB, rows []T, id func(int) *int32, ids map[int32]int32) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var generated []int32
+	if id != nil {
+		generated = make([]int32, len(rows))
+		for i := range rows {
+			generated[i] = *id(i)
+			*id(i) = 0
+		}
+	}
+
+	if err := tx.CreateInBatches(rows, batchSize).Error; err != nil {
+		return err
+	}
+
+	if ids != nil {
+		for i := range rows {
+			ids[generated[i]] = *id(i)
+		}
+	}
+	return nil
+}
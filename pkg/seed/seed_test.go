@@ -0,0 +1,83 @@
+package seed
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/pkg/models"
+)
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	if first, second := Generate(DefaultOptions()), Generate(DefaultOptions()); !reflect.DeepEqual(first, second) {
+		t.Error("the default options generated two different datasets")
+	}
+
+	options := DefaultOptions()
+	options.Seed++
+	if other := Generate(options); reflect.DeepEqual(other.Users, Generate(DefaultOptions()).Users) {
+		t.Error("a different seed generated the same users")
+	}
+}
+
+func TestGenerateIsConsistent(t *testing.T) {
+	options := DefaultOptions()
+	options.Users = 50
+	data := Generate(options)
+
+	if len(data.Users) != options.Users || len(data.Articles) != options.Users*options.ArticlesPerUser {
+		t.Fatalf("generated %d users and %d articles", len(data.Users), len(data.Articles))
+	}
+
+	revisions := make(map[int32]int)
+	for _, revision := range data.Revisions {
+		revisions[revision.IDArticle]++
+	}
+
+	favorites := make(map[int32]int32)
+	liked := make(map[[2]int32]bool)
+	for _, like := range data.Likes {
+		key := [2]int32{like.IDUser, like.IDArticle}
+		if liked[key] {
+			t.Errorf("user %d likes article %d twice", like.IDUser, like.IDArticle)
+		}
+		liked[key] = true
+		favorites[like.IDArticle]++
+	}
+
+	comments := make(map[int32]int32)
+	for i, comment := range data.Comments {
+		comments[comment.IDArticle]++
+		if i > 0 && comment.CreatedAt.Before(data.Comments[i-1].CreatedAt) {
+			t.Errorf("comment %d is older than the one before it", i)
+		}
+	}
+
+	for _, article := range data.Articles {
+		if revisions[article.ID] != 1 {
+			t.Errorf("article %d has %d revisions, want 1", article.ID, revisions[article.ID])
+		}
+		if article.FavoritesCount != favorites[article.ID] || article.CommentsCount != comments[article.ID] {
+			t.Errorf("article %d counts %d favorites and %d comments, has %d and %d",
+				article.ID, article.FavoritesCount, article.CommentsCount, favorites[article.ID], comments[article.ID])
+		}
+		if article.Status != models.ArticleStatusPublished && (favorites[article.ID] != 0 || comments[article.ID] != 0) {
+			t.Errorf("unpublished article %d has favorites or comments", article.ID)
+		}
+	}
+
+	for _, follow := range data.Follows {
+		if follow.IDUserA == follow.IDUserB {
+			t.Errorf("user %d follows themselves", follow.IDUserA)
+		}
+	}
+
+	tags := make(map[int32]bool)
+	for _, tag := range data.Tags {
+		tags[tag.ID] = true
+	}
+	for _, articleTag := range data.ArticleTags {
+		if !tags[articleTag.IDTag] || articleTag.IDArticle < 1 || int(articleTag.IDArticle) > len(data.Articles) {
+			t.Errorf("article tag %+v points nowhere", articleTag)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/RayhanAnandhias/realworld-project-golang/configs"
+)
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// Storage keeps uploaded media. Keys are slash separated relative paths such
+// as "avatars/12/3f2a9c.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose key starts with prefix, sorted by key.
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+func New(config *configs.Config) (Storage, error) {
+	switch config.StorageBackend {
+	case BackendLocal:
+		return NewLocalStorage(config.StorageLocalDir)
+	case BackendS3:
+		return NewS3Storage(S3Options{
+			Endpoint:        config.S3Endpoint,
+			Region:          config.S3Region,
+			Bucket:          config.S3Bucket,
+			AccessKeyID:     config.S3AccessKeyID,
+			SecretAccessKey: config.S3SecretAccessKey,
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", config.StorageBackend)
+	}
+}
+
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != key || strings.HasPrefix(cleaned, "..") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at a row of a listing ordered by (key, ID), where the key is
+// either a time or a number. Sort names the ordering the cursor belongs to.
+// Before means the page wanted is the one ending just before the row rather
+// than starting just after it.
+type Cursor struct {
+	Sort   string     `json:"s,omitempty"`
+	Time   *time.Time `json:"t,omitempty"`
+	Number *float64   `json:"n,omitempty"`
+	ID     int32      `json:"i"`
+	Before bool       `json:"b,omitempty"`
+}
+
+// NewCursor builds a cursor for a row whose key is a time.Time or a float64.
+func NewCursor(sort string, key interface{}, id int32, before bool) Cursor {
+	c := Cursor{Sort: sort, ID: id, Before: before}
+	switch k := key.(type) {
+	case time.Time:
+		c.Time = &k
+	case float64:
+		c.Number = &k
+	}
+	return c
+}
+
+// Key returns the key of the row the cursor points at.
+func (c *Cursor) Key() interface{} {
+	if c.Time != nil {
+		return *c.Time
+	}
+	if c.Number != nil {
+		return *c.Number
+	}
+	return nil
+}
+
+// EncodeCursor returns c as an opaque URL-safe string.
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 || (c.Time == nil) == (c.Number == nil) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
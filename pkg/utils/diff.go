@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DiffEqual  = ' '
+	DiffInsert = '+'
+	DiffDelete = '-'
+)
+
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// DiffLines computes a shortest line edit script from a to b using Myers'
+// algorithm.
+func DiffLines(a []string, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+
+		if done {
+			break
+		}
+	}
+
+	var reversed []DiffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffLine{DiffEqual, a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, DiffLine{DiffInsert, b[y-1]})
+			} else {
+				reversed = append(reversed, DiffLine{DiffDelete, a[x-1]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	lines := make([]DiffLine, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// UnifiedDiff renders the changes from one text to another in unified diff
+// format with the given number of context lines. It returns an empty string
+// when both texts are equal.
+func UnifiedDiff(from string, to string, fromName string, toName string, context int) string {
+	lines := DiffLines(splitLines(from), splitLines(to))
+
+	var changes []int
+	for i, line := range lines {
+		if line.Op != DiffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString("--- " + fromName + "\n")
+	out.WriteString("+++ " + toName + "\n")
+
+	// line numbers in a and b at the start of every entry of lines
+	aLine := make([]int, len(lines)+1)
+	bLine := make([]int, len(lines)+1)
+	for i, line := range lines {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if line.Op != DiffInsert {
+			aLine[i+1]++
+		}
+		if line.Op != DiffDelete {
+			bLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(changes); {
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context {
+			j++
+		}
+
+		start := changes[i] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		aStart, aLen := aLine[start], aLine[end]-aLine[start]
+		bStart, bLen := bLine[start], bLine[end]-bLine[start]
+		if aLen > 0 {
+			aStart++
+		}
+		if bLen > 0 {
+			bStart++
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, line := range lines[start:end] {
+			out.WriteByte(line.Op)
+			out.WriteString(line.Text)
+			out.WriteByte('\n')
+		}
+
+		i = j + 1
+	}
+
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/base32"
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -22,3 +25,16 @@ func GenerateSlug(s string) string {
 	loweredString := strings.ToLower(trimmedString)
 	return stringRegex.ReplaceAllString(loweredString, "-")
 }
+
+func GenerateInviteCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate invite code: %w", err)
+	}
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)), nil
+}
+
+// EscapeLike escapes the LIKE/ILIKE wildcards in s, to be used with ESCAPE '\'.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
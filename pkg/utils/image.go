@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxImagePixels guards against decompression bombs, checked before decoding.
+const maxImagePixels = 40_000_000
+
+var ErrUnsupportedImage = errors.New("unsupported image type, expected jpeg, png, gif or webp")
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+type ProcessedImage struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// SniffImageType detects the content type from the data itself, ignoring
+// whatever the client claimed.
+func SniffImageType(data []byte) (string, error) {
+	contentType := mimetype.Detect(data).String()
+	if !allowedImageTypes[contentType] {
+		return contentType, ErrUnsupportedImage
+	}
+	return contentType, nil
+}
+
+// ResizeImage fits the image inside a maxSize x maxSize box, never upscaling.
+// With square set, the image is center-cropped to a square first.
+// JPEG and WebP sources are encoded as JPEG, everything else as PNG.
+func ResizeImage(data []byte, maxSize int, square bool) (*ProcessedImage, error) {
+	contentType, err := SniffImageType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not read image: %w", err)
+	} else if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("image is too large (%dx%d)", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	if square {
+		side := bounds.Dx()
+		if bounds.Dy() < side {
+			side = bounds.Dy()
+		}
+		x0 := bounds.Min.X + (bounds.Dx()-side)/2
+		y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+		bounds = image.Rect(x0, y0, x0+side, y0+side)
+	}
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = height * maxSize / width
+			width = maxSize
+		} else {
+			width = width * maxSize / height
+			height = maxSize
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	processed := &ProcessedImage{Width: width, Height: height}
+
+	if contentType == "image/jpeg" || contentType == "image/webp" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		processed.ContentType = "image/jpeg"
+		processed.Extension = ".jpg"
+	} else {
+		err = png.Encode(&buf, dst)
+		processed.ContentType = "image/png"
+		processed.Extension = ".png"
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not encode image: %w", err)
+	}
+
+	processed.Data = buf.Bytes()
+	return processed, nil
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Link is a single web link as defined by RFC 8288.
+type Link struct {
+	URL string
+	Rel string
+}
+
+// FormatLinks renders links as the value of a Link header.
+func FormatLinks(links []Link) string {
+	parts := make([]string, 0, len(links))
+	for _, link := range links {
+		parts = append(parts, "<"+link.URL+`>; rel="`+link.Rel+`"`)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WithQuery returns the path and query of u with the given parameters set,
+// removing the ones set to an empty string.
+func WithQuery(u *url.URL, params map[string]string) string {
+	query := u.Query()
+	for key, value := range params {
+		if len(value) == 0 {
+			query.Del(key)
+		} else {
+			query.Set(key, value)
+		}
+	}
+
+	target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return target.String()
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,3 +20,12 @@ func HashPassword(password string) (string, error) {
 func VerifyPassword(hashedPassword string, candidatePassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(candidatePassword))
 }
+
+// GeneratePassword returns a random password for accounts an admin resets.
+func GeneratePassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}